@@ -0,0 +1,49 @@
+package soopay
+
+import (
+	"context"
+	"time"
+)
+
+const serviceQueryBalance = "query_mer_balance"
+
+// BalanceInfo 商户账户余额
+type BalanceInfo struct {
+	Available   Amount    // 可用余额
+	Frozen      Amount    // 冻结金额
+	Currency    string    // 币种，如：RMB
+	RequestTime time.Time // 发起查询的时间（取自客户端时钟，见 WithClock），网关不返回余额的时点
+}
+
+// QueryBalance 商户账户余额查询；网关拒绝请求时返回 ResponseError
+func (c *Client) QueryBalance(ctx context.Context) (*BalanceInfo, error) {
+	reqTime := c.now()
+
+	ret, err := c.Do(ctx, serviceQueryBalance, V{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkRetCode(ret); err != nil {
+		return nil, err
+	}
+
+	available, err := parseAmount(ret.Get("balance"))
+	if err != nil {
+		return nil, err
+	}
+
+	frozen, err := parseAmount(ret.Get("frozen_balance"))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &BalanceInfo{
+		Available:   available,
+		Frozen:      frozen,
+		Currency:    ret.Get("amt_type"),
+		RequestTime: reqTime,
+	}
+
+	return info, nil
+}
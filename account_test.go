@@ -0,0 +1,53 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBalance(t *testing.T) {
+	gateway := newTestClient(t)
+
+	var (
+		form  V
+		reply V
+	)
+
+	now := time.Date(2023, 12, 1, 10, 30, 0, 0, gatewayLocation)
+
+	cli := newTestClient(t, WithClock(ClockFunc(func() time.Time { return now })), WithHTTPClient(&mockHTTPClient{fn: func(n int, body []byte) (*http.Response, error) {
+		form, _ = ParseV(string(body))
+
+		html, _ := gateway.ReplyHTML(reply)
+		return mockResponse(http.StatusOK, html), nil
+	}}))
+
+	reply = V{"ret_code": OK, "balance": "10000", "frozen_balance": "500", "amt_type": "RMB"}
+
+	info, err := cli.QueryBalance(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, serviceQueryBalance, form.Get("service"))
+	assert.Equal(t, Amount(10000), info.Available)
+	assert.Equal(t, Amount(500), info.Frozen)
+	assert.Equal(t, "RMB", info.Currency)
+	assert.True(t, now.Equal(info.RequestTime))
+
+	reply = V{"ret_code": OK, "balance": "abc"}
+
+	_, err = cli.QueryBalance(context.Background())
+	assert.NotNil(t, err)
+
+	// 业务错误
+	reply = V{"ret_code": "00060780", "ret_msg": "merchant not allowed"}
+
+	_, err = cli.QueryBalance(context.Background())
+
+	var respErr *ResponseError
+	assert.True(t, errors.As(err, &respErr))
+	assert.Equal(t, "00060780", respErr.Code)
+}
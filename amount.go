@@ -0,0 +1,75 @@
+package soopay
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Amount 金额，单位：分
+type Amount int64
+
+// AmountFromCents 通过「分」生成金额
+func AmountFromCents(cents int64) Amount {
+	return Amount(cents)
+}
+
+// AmountFromYuan 通过「元」生成金额，如：12.34；
+// 注意：最多支持两位小数，超出精度时返回错误而非四舍五入
+func AmountFromYuan(yuan string) (Amount, error) {
+	s := strings.TrimSpace(yuan)
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	if len(intPart) == 0 || strings.HasPrefix(intPart, "-") || strings.HasPrefix(intPart, "+") {
+		return 0, fmt.Errorf("invalid yuan amount %q", yuan)
+	}
+
+	if len(fracPart) > 2 {
+		return 0, fmt.Errorf("invalid yuan amount %q: at most 2 decimal places", yuan)
+	}
+
+	i, err := strconv.ParseInt(intPart+fracPart+strings.Repeat("0", 2-len(fracPart)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid yuan amount %q", yuan)
+	}
+
+	return Amount(i), nil
+}
+
+// Cents 返回金额（单位：分）
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// Yuan 返回金额（单位：元），如：12.34
+func (a Amount) Yuan() string {
+	sign := ""
+
+	cents := int64(a)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// String 返回网关要求的金额格式（单位：分）
+func (a Amount) String() string {
+	return strconv.FormatInt(int64(a), 10)
+}
+
+// parseAmount 解析网关返回的金额（单位：分）
+func parseAmount(s string) (Amount, error) {
+	if len(s) == 0 {
+		return 0, nil
+	}
+
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	return Amount(i), nil
+}
@@ -0,0 +1,40 @@
+package soopay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmount(t *testing.T) {
+	for yuan, cents := range map[string]int64{
+		"12.34": 1234,
+		"12.3":  1230,
+		"12":    1200,
+		"0.01":  1,
+		"0":     0,
+		"12.":   1200,
+	} {
+		a, err := AmountFromYuan(yuan)
+		assert.Nil(t, err, yuan)
+		assert.Equal(t, cents, a.Cents(), yuan)
+	}
+
+	for _, yuan := range []string{"", ".5", "-1", "12.345", "1a", "1.2.3"} {
+		_, err := AmountFromYuan(yuan)
+		assert.NotNil(t, err, yuan)
+	}
+
+	a := AmountFromCents(1234)
+	assert.Equal(t, "1234", a.String())
+	assert.Equal(t, "12.34", a.Yuan())
+	assert.Equal(t, "0.05", AmountFromCents(5).Yuan())
+	assert.Equal(t, "-1.05", AmountFromCents(-105).Yuan())
+
+	a, err := parseAmount("100")
+	assert.Nil(t, err)
+	assert.Equal(t, AmountFromCents(100), a)
+
+	_, err = parseAmount("1.00")
+	assert.EqualError(t, err, `invalid amount "1.00"`)
+}
@@ -0,0 +1,82 @@
+package soopay
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// newNonce 生成32位随机字符串
+func newNonce() (string, error) {
+	var b [16]byte
+
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b[:]), nil
+}
+
+// setReplayFields 设置防重放字段（time_stamp、nonce_str），参与签名
+func (c *Client) setReplayFields(data V) error {
+	if c.reqTimestamp {
+		data.Set("time_stamp", FormatGatewayTime(c.now()))
+	}
+
+	if c.reqNonce {
+		nonce, err := newNonce()
+		if err != nil {
+			return err
+		}
+
+		data.Set("nonce_str", nonce)
+	}
+
+	return nil
+}
+
+// checkNotifyTime 校验通知的 time_stamp 是否在允许的时间偏差内
+func (c *Client) checkNotifyTime(data V) error {
+	if c.notifyTolerance <= 0 {
+		return nil
+	}
+
+	ts := data.Get("time_stamp")
+	if len(ts) == 0 {
+		return errors.New("notify time_stamp is required")
+	}
+
+	t, err := ParseGatewayTime(ts)
+	if err != nil {
+		return fmt.Errorf("invalid notify time_stamp %q: %w", ts, err)
+	}
+
+	if d := c.now().Sub(t); d > c.notifyTolerance || d < -c.notifyTolerance {
+		return fmt.Errorf("notify time_stamp %q is outside the tolerance of %s", ts, c.notifyTolerance)
+	}
+
+	return nil
+}
+
+// WithRequestTimestamp 请求时自动添加 time_stamp 字段（格式：20060102150405，北京时间），参与签名，用于防重放
+func WithRequestTimestamp() Option {
+	return func(c *Client) {
+		c.reqTimestamp = true
+	}
+}
+
+// WithRequestNonce 请求时自动添加 nonce_str 字段（32位随机字符串），参与签名，用于防重放
+func WithRequestNonce() Option {
+	return func(c *Client) {
+		c.reqNonce = true
+	}
+}
+
+// WithNotifyTimestampTolerance 验证异步通知时校验 time_stamp 字段，缺失或与当前时间的偏差超过 d 时拒绝，用于防重放
+func WithNotifyTimestampTolerance(d time.Duration) Option {
+	return func(c *Client) {
+		c.notifyTolerance = d
+	}
+}
@@ -0,0 +1,81 @@
+package soopay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestReplayFields(t *testing.T) {
+	now := time.Date(2023, 12, 1, 10, 30, 0, 0, gatewayLocation)
+
+	cli := newTestClient(t, WithRequestTimestamp(), WithRequestNonce(), WithClock(ClockFunc(func() time.Time { return now })))
+
+	form, err := cli.BuildForm(serviceQueryOrder, V{"order_id": "202312010001"})
+	assert.Nil(t, err)
+
+	data, err := ParseV(form)
+	assert.Nil(t, err)
+	assert.Equal(t, "20231201103000", data.Get("time_stamp"))
+	assert.Len(t, data.Get("nonce_str"), 32)
+
+	// 参与签名
+	assert.Nil(t, cli.VerifySign(data))
+
+	data.Set("nonce_str", "0123456789abcdef0123456789abcdef")
+	assert.NotNil(t, cli.VerifySign(data))
+
+	// 每次请求的 nonce_str 不同
+	form2, err := cli.BuildForm(serviceQueryOrder, V{"order_id": "202312010001"})
+	assert.Nil(t, err)
+	assert.NotEqual(t, form, form2)
+}
+
+func TestNotifyTimestampTolerance(t *testing.T) {
+	now := time.Date(2023, 12, 1, 10, 30, 0, 0, gatewayLocation)
+
+	cli := newTestClient(t, WithClock(ClockFunc(func() time.Time { return now })), WithNotifyTimestampTolerance(5*time.Minute))
+
+	verify := func(data V) error {
+		query, err := cli.BuildForm("pay_result_notify", data)
+		assert.Nil(t, err)
+
+		_, err = cli.VerifyNotify(httptest.NewRequest(http.MethodGet, "/notify?"+query, nil))
+
+		return err
+	}
+
+	assert.Nil(t, verify(V{"order_id": "202312010001", "time_stamp": "20231201102600"}))
+	assert.EqualError(t, verify(V{"order_id": "202312010001", "time_stamp": "20231201102400"}), `notify time_stamp "20231201102400" is outside the tolerance of 5m0s`)
+	assert.EqualError(t, verify(V{"order_id": "202312010001"}), "notify time_stamp is required")
+}
+
+func TestVerifyFormTimestamp(t *testing.T) {
+	now := time.Date(2023, 12, 1, 10, 30, 0, 0, gatewayLocation)
+
+	cli := newTestClient(t, WithClock(ClockFunc(func() time.Time { return now })), WithNotifyTimestampTolerance(5*time.Minute))
+
+	form, err := cli.BuildForm("pay_result_notify", V{"order_id": "202312010001", "time_stamp": "20231201102600"})
+	assert.Nil(t, err)
+
+	ret, err := cli.VerifyForm([]byte(form))
+	assert.Nil(t, err)
+	assert.Equal(t, "202312010001", ret.Get("order_id"))
+
+	// 过期的通知，签名正确也拒绝
+	form, err = cli.BuildForm("pay_result_notify", V{"order_id": "202312010001", "time_stamp": "20231201102400"})
+	assert.Nil(t, err)
+
+	_, err = cli.VerifyForm([]byte(form))
+	assert.EqualError(t, err, `notify time_stamp "20231201102400" is outside the tolerance of 5m0s`)
+
+	vals, err := url.ParseQuery(form)
+	assert.Nil(t, err)
+
+	_, err = cli.VerifyQuery(vals)
+	assert.NotNil(t, err)
+}
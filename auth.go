@@ -0,0 +1,143 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+)
+
+// 预授权相关的服务
+const (
+	serviceAuthFreeze  = "pre_auth_freeze"
+	serviceAuthCapture = "pre_auth_capture"
+	serviceAuthCancel  = "pre_auth_cancel"
+)
+
+// AuthRequest 预授权（冻结资金）请求
+type AuthRequest struct {
+	OrderID   string // 商户预授权单号（必填）
+	Amount    Amount // 冻结金额（必填）
+	Subject   string // 商品描述（必填）
+	NotifyURL string // 异步通知地址
+	MerPriv   string // 商户私有域，原样返回
+}
+
+func (r *AuthRequest) validate() error {
+	if len(r.OrderID) == 0 {
+		return errors.New("order_id is required")
+	}
+
+	if r.Amount <= 0 {
+		return errors.New("amount must be greater than 0")
+	}
+
+	if len(r.Subject) == 0 {
+		return errors.New("subject is required")
+	}
+
+	return nil
+}
+
+func (r *AuthRequest) bizData() V {
+	v := V{}
+
+	v.Set("order_id", r.OrderID)
+	v.Set("amount", r.Amount.String())
+	v.Set("amt_type", "RMB")
+	v.Set("goods_inf", r.Subject)
+	v.Set("notify_url", r.NotifyURL)
+	v.Set("mer_priv", r.MerPriv)
+
+	return v
+}
+
+// AuthResponse 预授权结果
+type AuthResponse struct {
+	OrderID string // 商户预授权单号
+	AuthID  string // 平台预授权号，用于 AuthCapture 和 AuthCancel
+	Amount  Amount // 冻结金额
+	MerPriv string // 商户私有域
+}
+
+// AuthFreeze 预授权（冻结资金），之后通过 AuthCapture 完成扣款或通过 AuthCancel 解冻
+func (c *Client) AuthFreeze(ctx context.Context, req *AuthRequest) (*AuthResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	ret, err := c.Do(ctx, serviceAuthFreeze, req.bizData())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkRetCode(ret); err != nil {
+		return nil, err
+	}
+
+	amount, err := parseAmount(ret.Get("amount"))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &AuthResponse{
+		OrderID: ret.Get("order_id"),
+		AuthID:  ret.Get("auth_no"),
+		Amount:  amount,
+		MerPriv: ret.Get("mer_priv"),
+	}
+
+	return resp, nil
+}
+
+// CaptureResponse 预授权完成结果
+type CaptureResponse struct {
+	AuthID  string // 平台预授权号
+	TradeNO string // 平台交易号
+	Amount  Amount // 扣款金额
+}
+
+// AuthCapture 预授权完成（扣款），扣款金额不能超过冻结金额，剩余冻结资金由平台解冻
+func (c *Client) AuthCapture(ctx context.Context, authID string, amount Amount) (*CaptureResponse, error) {
+	if len(authID) == 0 {
+		return nil, errors.New("auth_no is required")
+	}
+
+	if amount <= 0 {
+		return nil, errors.New("amount must be greater than 0")
+	}
+
+	ret, err := c.Do(ctx, serviceAuthCapture, V{"auth_no": authID, "amount": amount.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkRetCode(ret); err != nil {
+		return nil, err
+	}
+
+	captured, err := parseAmount(ret.Get("amount"))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &CaptureResponse{
+		AuthID:  ret.Get("auth_no"),
+		TradeNO: ret.Get("trade_no"),
+		Amount:  captured,
+	}
+
+	return resp, nil
+}
+
+// AuthCancel 预授权撤销（解冻全部冻结资金）
+func (c *Client) AuthCancel(ctx context.Context, authID string) error {
+	if len(authID) == 0 {
+		return errors.New("auth_no is required")
+	}
+
+	ret, err := c.Do(ctx, serviceAuthCancel, V{"auth_no": authID})
+	if err != nil {
+		return err
+	}
+
+	return checkRetCode(ret)
+}
@@ -0,0 +1,51 @@
+package soopay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthRequest(t *testing.T) {
+	req := &AuthRequest{
+		OrderID: "A202312010001",
+		Amount:  10000,
+		Subject: "押金",
+	}
+	assert.Nil(t, req.validate())
+	assert.Equal(t, "amount=10000&amt_type=RMB&goods_inf=押金&order_id=A202312010001", req.bizData().Encode("=", "&", WithEmptyMode(EmptyIgnore)))
+
+	req.Subject = ""
+	assert.EqualError(t, req.validate(), "subject is required")
+}
+
+func TestAuthCapture(t *testing.T) {
+	cli := newTestClient(t)
+
+	body, err := cli.ReplyHTML(V{"ret_code": OK, "auth_no": "P3312010001", "trade_no": "3312010001", "amount": "8000"})
+	assert.Nil(t, err)
+
+	var form V
+
+	cli.httpCli = &mockHTTPClient{
+		fn: func(n int, b []byte) (*http.Response, error) {
+			form, _ = ParseV(string(b))
+			return mockResponse(http.StatusOK, body), nil
+		},
+	}
+
+	resp, err := cli.AuthCapture(context.Background(), "P3312010001", 8000)
+	assert.Nil(t, err)
+	assert.Equal(t, serviceAuthCapture, form.Get("service"))
+	assert.Equal(t, "8000", form.Get("amount"))
+	assert.Equal(t, "3312010001", resp.TradeNO)
+	assert.Equal(t, Amount(8000), resp.Amount)
+
+	_, err = cli.AuthCapture(context.Background(), "P3312010001", 0)
+	assert.EqualError(t, err, "amount must be greater than 0")
+
+	assert.Nil(t, cli.AuthCancel(context.Background(), "P3312010001"))
+	assert.Equal(t, serviceAuthCancel, form.Get("service"))
+}
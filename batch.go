@@ -0,0 +1,107 @@
+package soopay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// defaultBatchConcurrency 批量查询的默认并发数
+const defaultBatchConcurrency = 8
+
+// OrderError 批量查询中单个订单的错误
+type OrderError struct {
+	Index   int    // 订单在 orderIDs 中的下标
+	OrderID string // 商户订单号
+	Err     error
+}
+
+// BatchError 批量查询的错误，包含全部查询失败的订单
+type BatchError struct {
+	Errs []OrderError
+}
+
+// Error 实现 error 接口
+func (e *BatchError) Error() string {
+	var buf strings.Builder
+
+	fmt.Fprintf(&buf, "%d order(s) failed", len(e.Errs))
+
+	for i, v := range e.Errs {
+		if i == 0 {
+			buf.WriteString(": ")
+		} else {
+			buf.WriteString("; ")
+		}
+
+		fmt.Fprintf(&buf, "%s: %v", v.OrderID, v.Err)
+	}
+
+	return buf.String()
+}
+
+// Unwrap 用于 errors.Is 和 errors.As 判断（如：errors.Is(err, ErrOrderNotFound)）
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errs))
+	for _, v := range e.Errs {
+		errs = append(errs, v.Err)
+	}
+
+	return errs
+}
+
+// BatchQueryOrder 批量订单查询；网关未提供批量查询服务，通过并发调用 QueryOrder 实现（并发数见 WithBatchConcurrency）；
+// 返回的结果与 orderIDs 一一对应（长度及顺序一致），查询失败的订单对应 nil，并同时返回 BatchError（含各订单的下标及错误）
+func (c *Client) BatchQueryOrder(ctx context.Context, orderIDs []string) ([]*OrderStatus, error) {
+	concurrency := c.batchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	var (
+		wg      sync.WaitGroup
+		results = make([]*OrderStatus, len(orderIDs))
+		errs    = make([]error, len(orderIDs))
+		tasks   = make(chan int)
+	)
+
+	for i := 0; i < concurrency && i < len(orderIDs); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for idx := range tasks {
+				results[idx], errs[idx] = c.QueryOrder(ctx, orderIDs[idx])
+			}
+		}()
+	}
+
+	for i := range orderIDs {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		tasks <- i
+	}
+
+	close(tasks)
+	wg.Wait()
+
+	batchErr := new(BatchError)
+
+	for i, err := range errs {
+		if err != nil {
+			results[i] = nil
+			batchErr.Errs = append(batchErr.Errs, OrderError{Index: i, OrderID: orderIDs[i], Err: err})
+		}
+	}
+
+	if len(batchErr.Errs) != 0 {
+		return results, batchErr
+	}
+
+	return results, nil
+}
@@ -0,0 +1,79 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type batchHTTPClient struct {
+	running int32
+	peak    int32
+	fn      func(form V) string
+}
+
+func (m *batchHTTPClient) Do(ctx context.Context, method, reqURL string, body []byte, options ...HTTPOption) (*http.Response, error) {
+	n := atomic.AddInt32(&m.running, 1)
+	defer atomic.AddInt32(&m.running, -1)
+
+	for {
+		peak := atomic.LoadInt32(&m.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&m.peak, peak, n) {
+			break
+		}
+	}
+
+	form, _ := ParseV(string(body))
+
+	return mockResponse(http.StatusOK, m.fn(form)), nil
+}
+
+func TestBatchQueryOrder(t *testing.T) {
+	cli := newTestClient(t, WithBatchConcurrency(2))
+
+	mock := &batchHTTPClient{
+		fn: func(form V) string {
+			orderID := form.Get("order_id")
+
+			ret := V{"ret_code": OK, "order_id": orderID, "trade_state": "TRADE_SUCCESS", "amount": "100"}
+			if orderID == "O3" {
+				ret = V{"ret_code": codeOrderNotFound, "ret_msg": "订单不存在"}
+			}
+
+			body, _ := cli.ReplyHTML(ret)
+
+			return body
+		},
+	}
+	cli.httpCli = mock
+
+	orders, err := cli.BatchQueryOrder(context.Background(), []string{"O1", "O2", "O3", "O4", "O5"})
+	assert.Len(t, orders, 5)
+	assert.Equal(t, "O1", orders[0].OrderID)
+	assert.Nil(t, orders[2])
+	assert.Equal(t, "O5", orders[4].OrderID)
+	assert.LessOrEqual(t, mock.peak, int32(2))
+
+	var batchErr *BatchError
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Len(t, batchErr.Errs, 1)
+	assert.Equal(t, 2, batchErr.Errs[0].Index)
+	assert.Equal(t, "O3", batchErr.Errs[0].OrderID)
+	assert.True(t, errors.Is(err, ErrOrderNotFound))
+
+	orders, err = cli.BatchQueryOrder(context.Background(), []string{"O1"})
+	assert.Nil(t, err)
+	assert.Len(t, orders, 1)
+
+	// Context 取消后不再发起请求
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	orders, err = cli.BatchQueryOrder(ctx, []string{"O1", "O2"})
+	assert.Equal(t, []*OrderStatus{nil, nil}, orders)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
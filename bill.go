@@ -0,0 +1,102 @@
+package soopay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+const serviceDownloadBill = "download_settle_file"
+
+// DownloadBill 下载对账文件；billType 为对账文件类型，返回的 io.ReadCloser 需由调用方关闭；
+// billDate 按网关时区（北京时间）取日期，与 billDate 的时区无关
+// 注意：网关返回错误信息（HTML报文）而非文件时，返回 ResponseError
+func (c *Client) DownloadBill(ctx context.Context, billDate time.Time, billType string) (rc io.ReadCloser, err error) {
+	log := c.newReqLog()
+	defer func() {
+		log.SetError(err)
+		log.LogResponse(ctx, c.logger)
+	}()
+
+	reqCtx, cancel := c.withTimeout(ctx)
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+
+	bizData := V{
+		"settle_date": billDate.In(gatewayLocation).Format("20060102"),
+		"settle_type": billType,
+	}
+
+	resp, err := c.post(reqCtx, serviceDownloadBill, bizData, log)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(resp.Body)
+
+	// 网关返回错误时为HTML报文
+	if b, _ := r.Peek(htmlPeekSize); !c.isHTMLResponse(resp.Header, b) {
+		return &billReader{Reader: r, body: resp.Body, cancel: cancel}, nil
+	}
+
+	defer resp.Body.Close()
+
+	b, err := c.readBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	log.SetRespBody(string(b))
+
+	ret, err := c.VerifyHTML(b)
+	if err != nil {
+		// 网关返回了错误页面
+		if errors.As(err, new(*metaNotFoundError)) {
+			return nil, newGatewayError(resp.StatusCode, b)
+		}
+
+		return nil, err
+	}
+
+	if err = checkRetCode(ret); err != nil {
+		return nil, err
+	}
+
+	return nil, errors.New("err unexpected response without bill file")
+}
+
+// htmlPeekSize 识别HTML报文时读取的最大字节数
+const htmlPeekSize = 1024
+
+// isHTMLResponse 判断返回的是否为HTML报文（而非对账文件）：Content-Type 为 text/html，或包含网关的 meta 签名标签；
+// 不能仅以「<」开头判断，对账文件（如：XML）也可能以「<」开头
+func (c *Client) isHTMLResponse(header http.Header, b []byte) bool {
+	if mt, _, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil && mt == "text/html" {
+		return true
+	}
+
+	s := bytes.ToLower(b)
+
+	return bytes.Contains(s, []byte("<meta")) && bytes.Contains(s, bytes.ToLower([]byte(c.metaName)))
+}
+
+type billReader struct {
+	*bufio.Reader
+
+	body   io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *billReader) Close() error {
+	defer r.cancel()
+
+	return r.body.Close()
+}
@@ -0,0 +1,89 @@
+package soopay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadBill(t *testing.T) {
+	var form string
+
+	mock := &mockHTTPClient{
+		fn: func(n int, body []byte) (*http.Response, error) {
+			form = string(body)
+			return mockResponse(http.StatusOK, "order_id,amount\n202312010001,100\n"), nil
+		},
+	}
+
+	cli := newTestClient(t)
+	cli.httpCli = mock
+
+	rc, err := cli.DownloadBill(context.Background(), time.Date(2023, 12, 1, 0, 0, 0, 0, gatewayLocation), "ORDER")
+	assert.Nil(t, err)
+
+	b, err := io.ReadAll(rc)
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+	assert.Equal(t, "order_id,amount\n202312010001,100\n", string(b))
+	assert.Contains(t, form, "settle_date=20231201")
+
+	// 网关返回HTML错误报文
+	mock.fn = func(n int, body []byte) (*http.Response, error) {
+		return mockResponse(http.StatusOK, `<html><head><META NAME="MobilePayPlatform" CONTENT=""/></head></html>`), nil
+	}
+
+	_, err = cli.DownloadBill(context.Background(), time.Now(), "ORDER")
+	assert.EqualError(t, err, "err empty meta content")
+
+	// Content-Type 为 text/html 的错误页面
+	mock.fn = func(n int, body []byte) (*http.Response, error) {
+		resp := mockResponse(http.StatusOK, `<html><body>system busy</body></html>`)
+		resp.Header.Set("Content-Type", "text/html; charset=GBK")
+		return resp, nil
+	}
+
+	_, err = cli.DownloadBill(context.Background(), time.Now(), "ORDER")
+	assert.ErrorAs(t, err, new(*GatewayError))
+
+	// 以「<」开头的对账文件（如：XML）
+	mock.fn = func(n int, body []byte) (*http.Response, error) {
+		resp := mockResponse(http.StatusOK, `<?xml version="1.0"?><bill><order_id>202312010001</order_id></bill>`)
+		resp.Header.Set("Content-Type", "application/octet-stream")
+		return resp, nil
+	}
+
+	rc, err = cli.DownloadBill(context.Background(), time.Now(), "ORDER")
+	assert.Nil(t, err)
+
+	b, err = io.ReadAll(rc)
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+	assert.Contains(t, string(b), "<bill>")
+}
+
+func TestDownloadBillDate(t *testing.T) {
+	var form string
+
+	cli := newTestClient(t, WithHTTPClient(&mockHTTPClient{
+		fn: func(n int, body []byte) (*http.Response, error) {
+			form = string(body)
+			return mockResponse(http.StatusOK, "order_id,amount\n"), nil
+		},
+	}))
+
+	// UTC 16:00 后已是北京时间的次日
+	rc, err := cli.DownloadBill(context.Background(), time.Date(2023, 12, 1, 16, 30, 0, 0, time.UTC), "ORDER")
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+	assert.Contains(t, form, "settle_date=20231202")
+
+	rc, err = cli.DownloadBill(context.Background(), time.Date(2023, 12, 1, 15, 59, 0, 0, time.UTC), "ORDER")
+	assert.Nil(t, err)
+	assert.Nil(t, rc.Close())
+	assert.Contains(t, form, "settle_date=20231201")
+}
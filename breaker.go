@@ -0,0 +1,148 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // 关闭（正常请求）
+	BreakerOpen                         // 打开（快速失败）
+	BreakerHalfOpen                     // 半开（放行一个探测请求）
+)
+
+// String 实现 fmt.Stringer 接口
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// ErrCircuitOpen 熔断器打开时快速失败返回的错误，请求未发送至网关
+var ErrCircuitOpen = errors.New("soopay: circuit breaker is open")
+
+// BreakerObserver 可选接口；若 Metrics 实现了该接口，则在熔断器状态变化时调用
+type BreakerObserver interface {
+	ObserveBreakerState(from, to BreakerState)
+}
+
+// breaker 熔断器：连续失败 threshold 次后打开并快速失败，cooldown 后半开放行一个探测请求，
+// 探测成功则关闭，失败则重新打开；仅连接错误和HTTP 5xx 计为失败，业务错误不计入
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	onChange  func(ctx context.Context, from, to BreakerState)
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// allow 判断是否放行请求
+func (b *breaker) allow(ctx context.Context) error {
+	b.mu.Lock()
+
+	from := b.state
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			b.mu.Unlock()
+			return ErrCircuitOpen
+		}
+
+		b.state = BreakerHalfOpen
+		b.probing = true
+	case BreakerHalfOpen:
+		if b.probing {
+			b.mu.Unlock()
+			return ErrCircuitOpen
+		}
+
+		b.probing = true
+	}
+
+	to := b.state
+
+	b.mu.Unlock()
+
+	b.notify(ctx, from, to)
+
+	return nil
+}
+
+// done 记录请求结果；请求因 Context 取消或超时结束时既不计为成功也不计为失败
+func (b *breaker) done(ctx context.Context, resp *http.Response, err error) {
+	b.mu.Lock()
+
+	from := b.state
+
+	b.probing = false
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		b.failures++
+
+		if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+			b.state = BreakerOpen
+			b.openedAt = b.now()
+		}
+	default:
+		b.failures = 0
+		b.state = BreakerClosed
+	}
+
+	to := b.state
+
+	b.mu.Unlock()
+
+	b.notify(ctx, from, to)
+}
+
+func (b *breaker) notify(ctx context.Context, from, to BreakerState) {
+	if from != to && b.onChange != nil {
+		b.onChange(ctx, from, to)
+	}
+}
+
+// breakerChanged 熔断器状态变化时记录警告日志和指标
+func (c *Client) breakerChanged(ctx context.Context, from, to BreakerState) {
+	c.logWarn(ctx, "soopay: circuit breaker "+from.String()+" -> "+to.String())
+
+	if m, ok := c.metrics.(BreakerObserver); ok {
+		m.ObserveBreakerState(from, to)
+	}
+}
+
+// BreakerState 返回熔断器的当前状态；未设置熔断器时始终为 BreakerClosed
+func (c *Client) BreakerState() BreakerState {
+	if c.breaker == nil {
+		return BreakerClosed
+	}
+
+	return c.breaker.State()
+}
@@ -0,0 +1,130 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type breakerMetrics struct {
+	testMetrics
+
+	states []BreakerState
+}
+
+func (m *breakerMetrics) ObserveBreakerState(from, to BreakerState) {
+	m.states = append(m.states, to)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	logger := new(warnLogger)
+	metrics := new(breakerMetrics)
+
+	cli := newTestClient(t, WithCircuitBreaker(2, 50*time.Millisecond), WithStructuredLogger(logger), WithMetrics(metrics))
+
+	body, err := cli.ReplyHTML(V{"ret_code": codeOrderNotFound, "ret_msg": "订单不存在"})
+	assert.Nil(t, err)
+
+	down := true
+
+	mock := &mockHTTPClient{
+		fn: func(n int, b []byte) (*http.Response, error) {
+			if down {
+				return mockResponse(http.StatusBadGateway, ""), nil
+			}
+
+			return mockResponse(http.StatusOK, body), nil
+		},
+	}
+	cli.httpCli = mock
+
+	ctx := context.Background()
+
+	// 连续失败2次后打开
+	for i := 0; i < 2; i++ {
+		_, err = cli.Do(ctx, serviceQueryOrder, V{"order_id": "O1"})
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, BreakerOpen, cli.BreakerState())
+
+	// 快速失败，不发送请求
+	_, err = cli.Do(ctx, serviceQueryOrder, V{"order_id": "O1"})
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, 2, mock.calls)
+
+	// 冷却后半开探测，探测失败重新打开
+	time.Sleep(60 * time.Millisecond)
+
+	_, err = cli.Do(ctx, serviceQueryOrder, V{"order_id": "O1"})
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, BreakerOpen, cli.BreakerState())
+
+	// 探测成功后关闭；业务错误不计为失败
+	time.Sleep(60 * time.Millisecond)
+
+	down = false
+
+	_, err = cli.QueryOrder(ctx, "O1")
+	assert.True(t, errors.Is(err, ErrOrderNotFound))
+	assert.Equal(t, BreakerClosed, cli.BreakerState())
+
+	assert.Equal(t, []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}, metrics.states)
+	assert.Equal(t, "soopay: circuit breaker closed -> open", logger.warns[0])
+
+	assert.Panics(t, func() { WithCircuitBreaker(0, time.Second) })
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := &breaker{threshold: 1, cooldown: time.Millisecond, now: time.Now}
+
+	ctx := context.Background()
+
+	b.done(ctx, nil, errors.New("connection refused"))
+	assert.Equal(t, BreakerOpen, b.State())
+
+	time.Sleep(2 * time.Millisecond)
+
+	// 半开时仅放行一个探测请求
+	assert.Nil(t, b.allow(ctx))
+	assert.Equal(t, ErrCircuitOpen, b.allow(ctx))
+
+	// 探测请求被取消，不影响状态
+	b.done(ctx, nil, context.Canceled)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	assert.Nil(t, b.allow(ctx))
+}
+
+func TestBreakerRateLimit(t *testing.T) {
+	var calls int
+
+	cli := newTestClient(t, WithRateLimit(1, 1), WithCircuitBreaker(2, time.Minute), WithHTTPClient(httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		calls++
+		return mockResponse(http.StatusOK, "ok"), nil
+	})))
+
+	// 消耗令牌
+	_, _ = cli.Do(context.Background(), "query_order", V{"order_id": "202312010001"})
+	assert.Equal(t, 1, calls)
+
+	// 限流等待超出截止时间，请求未发送，不计为失败
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err := cli.Do(ctx, "query_order", V{"order_id": "202312010001"})
+		cancel()
+
+		assert.NotNil(t, err)
+		assert.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, BreakerClosed, cli.BreakerState())
+
+	// 请求超时不计为失败
+	b := &breaker{threshold: 1, cooldown: time.Minute, now: time.Now}
+	b.done(context.Background(), nil, context.DeadlineExceeded)
+	assert.Equal(t, BreakerClosed, b.State())
+}
@@ -0,0 +1,177 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+)
+
+// 绑卡相关的服务
+const (
+	serviceBindCardApply   = "bind_card_apply"
+	serviceBindCardConfirm = "bind_card_confirm"
+	serviceResendSMS       = "resend_sms"
+)
+
+// BindCardRequest 绑卡申请（发送短信验证码）；卡号、持卡人姓名、证件号及手机号会自动加密，无需调用 Encrypt
+type BindCardRequest struct {
+	OrderID      string // 商户绑卡申请单号（必填）
+	UserID       string // 商户侧用户标识（必填）
+	CardNO       string // 银行卡号（必填）
+	CardHolder   string // 持卡人姓名（必填）
+	IdentityType string // 证件类型，默认：IDENTITY_CARD（身份证）
+	IdentityCode string // 证件号（必填）
+	MobileNO     string // 银行预留手机号（必填）
+}
+
+func (r *BindCardRequest) validate() error {
+	if len(r.OrderID) == 0 {
+		return errors.New("order_id is required")
+	}
+
+	if len(r.UserID) == 0 {
+		return errors.New("mer_cust_id is required")
+	}
+
+	if len(r.CardNO) == 0 {
+		return errors.New("card_id is required")
+	}
+
+	if len(r.CardHolder) == 0 {
+		return errors.New("card_holder is required")
+	}
+
+	if len(r.IdentityCode) == 0 {
+		return errors.New("identity_code is required")
+	}
+
+	if len(r.MobileNO) == 0 {
+		return errors.New("media_id is required")
+	}
+
+	return nil
+}
+
+func (r *BindCardRequest) bizData(encrypt func(plain string) (string, error)) (V, error) {
+	v := V{}
+
+	sensitive := []struct {
+		key   string
+		value string
+	}{
+		{"card_id", r.CardNO},
+		{"card_holder", r.CardHolder},
+		{"identity_code", r.IdentityCode},
+		{"media_id", r.MobileNO},
+	}
+
+	for _, f := range sensitive {
+		cipher, err := encrypt(f.value)
+		if err != nil {
+			return nil, err
+		}
+
+		v.Set(f.key, cipher)
+	}
+
+	identityType := r.IdentityType
+	if len(identityType) == 0 {
+		identityType = "IDENTITY_CARD"
+	}
+
+	v.Set("order_id", r.OrderID)
+	v.Set("mer_cust_id", r.UserID)
+	v.Set("identity_type", identityType)
+	v.Set("media_type", "MOBILE")
+
+	return v, nil
+}
+
+// BindCardApplyResponse 绑卡申请结果
+type BindCardApplyResponse struct {
+	OrderID string // 商户绑卡申请单号
+	Token   string // 绑卡令牌，用于 BindCardConfirm
+}
+
+// BindCardApply 绑卡申请，平台向银行预留手机号发送短信验证码，之后通过 BindCardConfirm 确认绑卡
+func (c *Client) BindCardApply(ctx context.Context, req *BindCardRequest) (*BindCardApplyResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	bizData, err := req.bizData(c.Encrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := c.Do(ctx, serviceBindCardApply, bizData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkRetCode(ret); err != nil {
+		return nil, err
+	}
+
+	resp := &BindCardApplyResponse{
+		OrderID: ret.Get("order_id"),
+		Token:   ret.Get("trade_no"),
+	}
+
+	return resp, nil
+}
+
+// BindCardResult 绑卡结果
+type BindCardResult struct {
+	BindID   string // 协议号（绑卡ID），用于后续的协议支付
+	GateID   string // 银行编码
+	LastFour string // 卡号后四位
+}
+
+// BindCardConfirm 确认绑卡（校验短信验证码）
+func (c *Client) BindCardConfirm(ctx context.Context, token, smsCode string) (*BindCardResult, error) {
+	if len(token) == 0 {
+		return nil, errors.New("trade_no is required")
+	}
+
+	if len(smsCode) == 0 {
+		return nil, errors.New("verify_code is required")
+	}
+
+	ret, err := c.Do(ctx, serviceBindCardConfirm, V{"trade_no": token, "verify_code": smsCode})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkRetCode(ret); err != nil {
+		return nil, err
+	}
+
+	result := &BindCardResult{
+		BindID:   ret.Get("usr_pay_agreement_id"),
+		GateID:   ret.Get("gate_id"),
+		LastFour: ret.Get("last_four_cardid"),
+	}
+
+	return result, nil
+}
+
+// codeSMSTooFrequent 短信验证码发送过于频繁
+const codeSMSTooFrequent = "00200078"
+
+// ErrSMSTooFrequent 短信验证码发送过于频繁，请稍后重试
+var ErrSMSTooFrequent = &ResponseError{Code: codeSMSTooFrequent, Msg: "sms code requested too frequently"}
+
+// ResendSMS 重新发送短信验证码（绑卡、支付等流程）；
+// 发送过于频繁时返回的错误满足 errors.Is(err, ErrSMSTooFrequent)，调用方应提示用户并稍后重试
+func (c *Client) ResendSMS(ctx context.Context, token string) error {
+	if len(token) == 0 {
+		return errors.New("trade_no is required")
+	}
+
+	ret, err := c.Do(ctx, serviceResendSMS, V{"trade_no": token})
+	if err != nil {
+		return err
+	}
+
+	return checkRetCode(ret)
+}
@@ -0,0 +1,81 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindCardRequest(t *testing.T) {
+	cli := newTestClient(t)
+
+	req := &BindCardRequest{
+		OrderID:      "B202312010001",
+		UserID:       "U10001",
+		CardNO:       "6222020000000000000",
+		CardHolder:   "张三",
+		IdentityCode: "110101199001011234",
+		MobileNO:     "13800000000",
+	}
+	assert.Nil(t, req.validate())
+
+	v, err := req.bizData(cli.Encrypt)
+	assert.Nil(t, err)
+	assert.Equal(t, "IDENTITY_CARD", v.Get("identity_type"))
+
+	// 敏感字段自动加密
+	for k, plain := range map[string]string{
+		"card_id":       "6222020000000000000",
+		"card_holder":   "张三",
+		"identity_code": "110101199001011234",
+		"media_id":      "13800000000",
+	} {
+		s, err := cli.Decrypt(v.Get(k))
+		assert.Nil(t, err)
+		assert.Equal(t, plain, s)
+	}
+
+	req.MobileNO = ""
+	assert.EqualError(t, req.validate(), "media_id is required")
+}
+
+func TestBindCardConfirm(t *testing.T) {
+	cli := newTestClient(t)
+
+	body, err := cli.ReplyHTML(V{"ret_code": OK, "usr_pay_agreement_id": "P2023120100001", "gate_id": "ICBC", "last_four_cardid": "0000"})
+	assert.Nil(t, err)
+
+	cli.httpCli = &mockHTTPClient{
+		fn: func(n int, b []byte) (*http.Response, error) {
+			return mockResponse(http.StatusOK, body), nil
+		},
+	}
+
+	result, err := cli.BindCardConfirm(context.Background(), "3312010001", "123456")
+	assert.Nil(t, err)
+	assert.Equal(t, "P2023120100001", result.BindID)
+	assert.Equal(t, "0000", result.LastFour)
+
+	_, err = cli.BindCardConfirm(context.Background(), "3312010001", "")
+	assert.EqualError(t, err, "verify_code is required")
+}
+
+func TestResendSMS(t *testing.T) {
+	cli := newTestClient(t)
+
+	body, err := cli.ReplyHTML(V{"ret_code": codeSMSTooFrequent, "ret_msg": "短信发送过于频繁"})
+	assert.Nil(t, err)
+
+	cli.httpCli = &mockHTTPClient{
+		fn: func(n int, b []byte) (*http.Response, error) {
+			return mockResponse(http.StatusOK, body), nil
+		},
+	}
+
+	err = cli.ResendSMS(context.Background(), "3312010001")
+	assert.True(t, errors.Is(err, ErrSMSTooFrequent))
+	assert.False(t, errors.Is(err, ErrOrderNotFound))
+}
@@ -4,25 +4,134 @@ import (
 	"bytes"
 	"context"
 	"crypto"
+	"crypto/tls"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/qiniu/iconv"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
 )
 
+// 生产环境网关
+const (
+	defaultGateway  = "https://pay.soopay.net/spay/pay/payservice.do"
+	prodGatewayHost = "pay.soopay.net"
+)
+
+// defaultUserAgent 默认的 User-Agent
+const defaultUserAgent = "soopay-go/1.0.0"
+
+// defaultVersion 默认的接口版本号
+const defaultVersion = "4.0"
+
+// defaultMetaName 默认的HTML报文 meta 标签名称
+const defaultMetaName = "MobilePayPlatform"
+
+// 返回报文格式（参数 res_format 的值）
+const (
+	ResFormatHTML = "HTML" // 默认，签名数据位于META标签中
+	ResFormatJSON = "JSON" // 签名数据为JSON对象
+	ResFormatXML  = "XML"  // 签名数据为XML根元素的子元素
+)
+
+// defaultMaxResponseBytes 默认的返回报文最大长度
+const defaultMaxResponseBytes = 4 << 20
+
 // Client 联动支付客户端
 type Client struct {
 	gateway string
+	env     Environment
 	mchID   string
 	prvKey  *PrivateKey
 	pubKey  *PublicKey
+	pubKeys []*PublicKey
 	httpCli HTTPClient
-	logger  func(ctx context.Context, data map[string]string)
+	logger  Logger
+
+	transport   *http.Transport // 默认HTTP客户端的Transport
+	clientCerts []tls.Certificate
+	poolOpts    []string // 已设置的连接池选项，使用自定义HTTP客户端时不生效
+
+	signHash        crypto.Hash
+	replyHash       crypto.Hash
+	verifyHashes    []crypto.Hash
+	verifyEmptyMode VEmptyMode
+	signer          Signer            // 请求签名方式
+	signers         map[string]Signer // 验签方式（sign_type -> Signer）
+
+	retryMax      int
+	retryBackoff  time.Duration
+	retryServices map[string]struct{}
+	timeout       time.Duration
+	maxRespBytes  int64
+	userAgent     string
+	header        http.Header
+	encFields     []string
+	metrics       Metrics
+	logRedact     func(string) string
+	logSample     float64
+	reqIDHeader   string
+	ctxHeaders    []string
+	ctxHeaderFn   func(ctx context.Context) http.Header
+	strictResp    bool
+	testMode      bool
+	idem          *idempotency
+	queryGroup    *singleflight.Group
+	pendingCodes  map[string]struct{}
+	version       string
+	resFormat     string
+
+	respCharset  string
+	respEncoding Encoding
+	reqEncoding  *Encoding
+
+	batchConcurrency int
+	limiter          *rate.Limiter
+	breaker          *breaker
+	metaName         string
+	clock            Clock
+
+	reqTimestamp    bool
+	reqNonce        bool
+	notifyTolerance time.Duration
+	httpTimeout     time.Duration
+	middlewares     []HTTPMiddleware
+	tracer          Tracer
+
+	signKey     string
+	signTypeKey string
+	encPadding  PaddingType
+	defaultBiz  V
+	baseCli     HTTPClient // 未经中间件包装的 HTTP Client
+}
+
+// Close 释放资源：关闭HTTP连接池中的空闲连接（自定义的 HTTPClient 需实现 CloseIdleConnections 方法），并清空幂等缓存；
+// Client 不会启动后台 goroutine，Close 后仍可继续使用（将重新建立连接）
+func (c *Client) Close() error {
+	if cli, ok := c.baseCli.(interface{ CloseIdleConnections() }); ok {
+		cli.CloseIdleConnections()
+	}
+
+	c.transport.CloseIdleConnections()
+
+	if c.idem != nil {
+		c.idem.reset()
+	}
+
+	return nil
 }
 
 // MchNO 返回商户编号
@@ -30,13 +139,24 @@ func (c *Client) MchID() string {
 	return c.mchID
 }
 
-// Encrypt 敏感数据RSA加密
+// Encrypt 敏感数据RSA加密（填充方式见 WithEncryptionPadding）
 func (c *Client) Encrypt(plain string) (string, error) {
 	if c.pubKey == nil {
-		return "", errors.New("public key is nil (forgotten configure?)")
+		return "", ErrNoPublicKey
+	}
+
+	var (
+		b   []byte
+		err error
+	)
+
+	switch c.encPadding {
+	case PaddingOAEPSHA256:
+		b, err = c.pubKey.EncryptOAEP(crypto.SHA256, []byte(plain))
+	default:
+		b, err = c.pubKey.Encrypt([]byte(plain))
 	}
 
-	b, err := c.pubKey.Encrypt([]byte(plain))
 	if err != nil {
 		return "", err
 	}
@@ -46,22 +166,34 @@ func (c *Client) Encrypt(plain string) (string, error) {
 
 // MustEncrypt 敏感数据RSA加密；若发生错误，则Panic
 func (c *Client) MustEncrypt(plain string) string {
-	if c.pubKey == nil {
-		panic(errors.New("public key is nil (forgotten configure?)"))
-	}
-
-	b, err := c.pubKey.Encrypt([]byte(plain))
+	cipher, err := c.Encrypt(plain)
 	if err != nil {
 		panic(err)
 	}
 
-	return base64.StdEncoding.EncodeToString(b)
+	return cipher
 }
 
-// Decrypt 敏感数据RSA解密
+// Decrypt 敏感数据RSA解密；
+// 未通过 WithResponseCharset 指定字符集时，若解密结果不是合法的UTF-8，则按 WithResponseEncoding 指定的编码（默认：GBK）转码
 func (c *Client) Decrypt(cipher string) (string, error) {
+	return c.decrypt(cipher, c.respCharset)
+}
+
+// DecryptField 解密网关返回数据中的敏感字段；
+// 未通过 WithResponseCharset 指定字符集时，根据返回数据中的 charset 字段判断是否需要转码
+func (c *Client) DecryptField(data V, key string) (string, error) {
+	charset := c.respCharset
+	if len(charset) == 0 {
+		charset = data.Get("charset")
+	}
+
+	return c.decrypt(data.Get(key), charset)
+}
+
+func (c *Client) decrypt(cipher, charset string) (string, error) {
 	if c.prvKey == nil {
-		return "", errors.New("private key is nil (forgotten configure?)")
+		return "", ErrNoPrivateKey
 	}
 
 	b, err := base64.StdEncoding.DecodeString(cipher)
@@ -69,186 +201,1333 @@ func (c *Client) Decrypt(cipher string) (string, error) {
 		return "", err
 	}
 
-	plain, err := c.prvKey.Decrypt(b)
+	var plain []byte
+
+	switch c.encPadding {
+	case PaddingOAEPSHA256:
+		plain, err = c.prvKey.DecryptOAEP(crypto.SHA256, b)
+	default:
+		plain, err = c.prvKey.Decrypt(b)
+	}
+
 	if err != nil {
 		return "", err
 	}
 
-	// convert gbk to utf-8
-	cd, err := iconv.Open("utf-8", "gbk")
+	enc := c.respEncoding
+
+	switch strings.ToUpper(charset) {
+	case "UTF-8", "UTF8":
+		return string(plain), nil
+	case "GBK", "GB2312":
+	case "GB18030":
+		enc = EncodingGB18030
+	default:
+		if utf8.Valid(plain) {
+			return string(plain), nil
+		}
+	}
+
+	// convert gbk/gb18030 to utf-8（非法字节替换为 U+FFFD）
+	b, err = enc.encoding().NewDecoder().Bytes(plain)
 	if err != nil {
 		return "", err
 	}
-	defer cd.Close()
 
-	return cd.ConvString(string(plain)), nil
+	return string(b), nil
 }
 
-// Do 发送请求
-func (c *Client) Do(ctx context.Context, service string, bizData V) (V, error) {
-	log := NewReqLog(http.MethodPost, c.gateway)
-	defer log.Do(ctx, c.logger)
+// Do 发送请求；公共参数及签名添加在 bizData 的副本上，不会修改 bizData，可重复使用；
+// options 仅作用于本次请求（如：设置请求头或Cookie），优先于 Client 的默认设置
+func (c *Client) Do(ctx context.Context, service string, bizData V, options ...HTTPOption) (V, error) {
+	resp, err := c.DoRaw(ctx, service, bizData, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// Ping 连通性检查（如：服务启动或就绪探针），通过查询一笔不存在的订单发送签名请求；
+// 返回报文验签通过即视为正常（业务错误如「订单不存在」亦视为正常），
+// 可提前发现证书过期、网络异常、网关地址错误等问题；请通过 Context 设置超时时间
+func (c *Client) Ping(ctx context.Context) error {
+	orderID := "PING" + FormatGatewayTime(c.now())
+
+	ret, err := c.Do(ctx, serviceQueryOrder, V{"order_id": orderID})
+	if err != nil {
+		return err
+	}
+
+	if !ret.Has("ret_code") {
+		return errors.New("ping: ret_code is missing in the response")
+	}
+
+	return nil
+}
+
+// Response 网关返回结果
+type Response struct {
+	StatusCode int         // HTTP状态码
+	Header     http.Header // HTTP响应头
+	Body       []byte      // 原始返回报文
+	Data       V           // 验签后的数据
+
+	now func() time.Time // 客户端的时钟（见 WithClock），用于计算 RetryAfter
+}
+
+// RetryAfter 解析响应头 Retry-After（秒数或HTTP日期），返回建议的重试等待时间；未设置或格式错误时返回 false；
+// HTTP日期按客户端的时钟（见 WithClock）计算等待时间
+func (r *Response) RetryAfter() (time.Duration, bool) {
+	v := r.Header.Get("Retry-After")
+	if len(v) == 0 {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+
+		return time.Duration(secs) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	now := time.Now
+	if r.now != nil {
+		now = r.now
+	}
+
+	if d := t.Sub(now()); d > 0 {
+		return d, true
+	}
+
+	return 0, true
+}
+
+// DoRaw 发送请求，并返回原始返回报文及验签后的数据；
+// 注意：验签失败时，返回的 Response 仍包含原始返回报文（Data 为 nil），便于与平台排查问题；
+// HTTP状态码不为200时，返回的 Response 包含状态码及响应头（如：通过 RetryAfter 获取重试等待时间）
+func (c *Client) DoRaw(ctx context.Context, service string, bizData V, options ...HTTPOption) (*Response, error) {
+	if c.idem != nil {
+		if key, ok := c.idem.key(service, bizData); ok {
+			return c.idem.do(ctx, key, func() (*Response, error) {
+				// 共享的请求不随调用方取消，但总有截止时间，超时后移除，后续请求可重新发送
+				reqCtx, cancel := c.sharedContext(ctx)
+				defer cancel()
+
+				return c.doRaw(reqCtx, service, bizData, options...)
+			})
+		}
+	}
+
+	return c.doRaw(ctx, service, bizData, options...)
+}
+
+func (c *Client) doRaw(ctx context.Context, service string, bizData V, options ...HTTPOption) (ret *Response, err error) {
+	// 未设置请求ID时自动生成
+	reqID := RequestIDFromContext(ctx)
+	if len(reqID) == 0 {
+		reqID = newRequestID()
+		ctx = WithRequestID(ctx, reqID)
+	}
+
+	var span Span
+	if c.tracer != nil {
+		ctx, span = c.tracer.Start(ctx, service)
+	}
+
+	log := c.newReqLog()
+	log.Set("request_id", reqID)
+	defer func() {
+		log.SetError(err)
+		log.LogResponse(ctx, c.logger)
+
+		if c.metrics != nil || span != nil {
+			c.observe(service, log, span, ret, err)
+		}
+	}()
+
+	reqCtx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.post(reqCtx, service, bizData, log, options...)
+	if err != nil {
+		if resp != nil {
+			return &Response{StatusCode: resp.StatusCode, Header: resp.Header, now: c.now}, err
+		}
+
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := c.readBody(resp.Body)
+	if err != nil {
+		// 读取过程中 Context 被取消或超时
+		if ctxErr := reqCtx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		return nil, err
+	}
+
+	log.SetRespBody(string(b))
+
+	ret = &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       b,
+		now:        c.now,
+	}
+
+	ret.Data, err = c.verifyResponse(b)
+	if err != nil {
+		// 网关返回了错误页面
+		if errors.As(err, new(*metaNotFoundError)) {
+			return ret, newGatewayError(resp.StatusCode, b)
+		}
+
+		return ret, &VerifyError{Err: err}
+	}
+
+	if c.strictResp {
+		if err = c.checkEcho(service, ret.Data); err != nil {
+			ret.Data = nil
+			return ret, &VerifyError{Err: err}
+		}
+	}
+
+	return ret, nil
+}
+
+// checkEcho 校验返回的 service 和 version 与请求一致，防止返回报文错配
+func (c *Client) checkEcho(service string, data V) error {
+	if v := data.Get("service"); v != service {
+		return fmt.Errorf("response service %q mismatches the request %q", v, service)
+	}
+
+	if v := data.Get("version"); len(v) != 0 && v != c.version {
+		return fmt.Errorf("response version %q mismatches the request %q", v, c.version)
+	}
+
+	return nil
+}
+
+// observe 记录请求指标并结束 Span；请求成功时，返回码不为 OK（且不为「处理中」）视为业务错误
+func (c *Client) observe(service string, log *ReqLog, span Span, ret *Response, err error) {
+	if err == nil && ret != nil && !c.isPending(ret.Data.Get("ret_code")) {
+		err = checkRetCode(ret.Data)
+	}
+
+	statusCode := log.Entry().StatusCode
+
+	if c.metrics != nil {
+		c.metrics.ObserveRequest(service, statusCode, time.Since(log.start), err)
+	}
+
+	if span != nil {
+		span.End(statusCode, err)
+	}
+}
+
+// verifyResponse 根据 res_format 解析并验签返回报文
+func (c *Client) verifyResponse(body []byte) (V, error) {
+	switch c.resFormat {
+	case ResFormatHTML:
+		return c.VerifyHTML(body)
+	case ResFormatJSON:
+		return c.VerifyJSON(body)
+	case ResFormatXML:
+		return c.VerifyXML(body)
+	}
+
+	// 其它格式，返回报文为 K-V 字符串
+	vals, err := url.ParseQuery(string(bytes.TrimSpace(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.verifyValues(vals)
+}
+
+// readBody 读取返回报文，超过最大长度时返回错误
+func (c *Client) readBody(r io.Reader) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, c.maxRespBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(b)) > c.maxRespBytes {
+		return nil, fmt.Errorf("response body exceeds the limit of %d bytes", c.maxRespBytes)
+	}
+
+	return b, nil
+}
 
+// post 签名并发送请求；HTTP状态码不为200时返回错误，同时返回 Body 已关闭的 Response，用于获取状态码及响应头
+func (c *Client) post(ctx context.Context, service string, bizData V, log *ReqLog, options ...HTTPOption) (*http.Response, error) {
 	form, err := c.reqForm(service, bizData)
 	if err != nil {
 		return nil, err
 	}
 
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-www-form-urlencoded")
+	header.Set("User-Agent", c.userAgent)
+
+	if len(c.reqIDHeader) != 0 {
+		if id := RequestIDFromContext(ctx); len(id) != 0 {
+			header.Set(c.reqIDHeader, id)
+		}
+	}
+
+	for k, vals := range c.header {
+		header[k] = vals
+	}
+
+	if c.ctxHeaderFn != nil {
+		if h := c.ctxHeaderFn(ctx); len(h) != 0 {
+			for _, k := range c.ctxHeaders {
+				if vals := h.Values(k); len(vals) != 0 {
+					header[k] = vals
+				}
+			}
+		}
+	}
+
+	reqOptions := make([]HTTPOption, 0, len(header)+len(options))
+	for k, vals := range header {
+		reqOptions = append(reqOptions, WithHTTPHeader(k, vals...))
+	}
+
+	// 本次请求的选项优先
+	reqOptions = append(reqOptions, options...)
+
+	// 记录实际发送的请求头
+	opts := &httpOptions{header: http.Header{}}
+	for _, f := range reqOptions {
+		f(opts)
+	}
+
+	log.SetReqHeader(opts.header)
 	log.SetReqBody(form)
+	log.LogRequest(ctx, c.logger)
 
-	resp, err := c.httpCli.Do(ctx, http.MethodPost, c.gateway, []byte(form))
+	resp, err := c.send(ctx, service, []byte(form), log, reqOptions...)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
 	log.SetRespHeader(resp.Header)
 	log.SetStatusCode(resp.StatusCode)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP Request Error, StatusCode = %d", resp.StatusCode)
+		drainBody(resp.Body)
+		return resp, fmt.Errorf("HTTP Request Error, StatusCode = %d", resp.StatusCode)
 	}
 
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return resp, nil
+}
 
-	log.SetRespBody(string(b))
+// newReqLog 生成请求日志，按设置进行脱敏及采样
+func (c *Client) newReqLog() *ReqLog {
+	log := NewReqLog(http.MethodPost, c.gateway)
+	log.redact = c.logRedact
+
+	if c.logSample < 1 && rand.Float64() >= c.logSample {
+		log.skip = true
+	}
 
-	return c.VerifyHTML(b)
+	return log
 }
 
-func (c *Client) reqForm(service string, bizData V) (string, error) {
-	if c.prvKey == nil {
-		return "", errors.New("private key is nil (forgotten configure?)")
+// withTimeout 若 Context 未设置截止时间，则使用默认超时时间
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.timeout <= 0 {
+		return ctx, func() {}
 	}
 
-	bizData.Set("service", service)
-	bizData.Set("charset", "UTF-8")
-	bizData.Set("sign_type", "RSA")
-	bizData.Set("res_format", "HTML")
-	bizData.Set("version", "4.0")
-	bizData.Set("mer_id", c.mchID)
-
-	signStr := bizData.Encode("=", "&", WithEmptyMode(EmptyIgnore), WithIgnoreKeys("sign", "sign_type"))
+	return context.WithTimeout(ctx, c.timeout)
+}
 
-	sign, err := c.prvKey.Sign(crypto.SHA1, []byte(signStr))
+// BuildForm 生成签名后的请求表单（application/x-www-form-urlencoded），但不发送请求；
+// 可用于由App或浏览器直接向网关提交表单的场景，签名逻辑与 Do 一致；
+// 与 Do 的请求体不同，表单的K-V经过url编码，避免值中的「&」截断字段、签名（Base64）中的「+」被解析为空格
+func (c *Client) BuildForm(service string, bizData V) (string, error) {
+	data, err := c.signData(service, bizData)
 	if err != nil {
 		return "", err
 	}
 
-	bizData.Set("sign", base64.StdEncoding.EncodeToString(sign))
+	return data.Encode("=", "&", WithEmptyMode(EmptyIgnore), WithKVEscape()), nil
+}
 
-	return bizData.Encode("=", "&", WithEmptyMode(EmptyIgnore)), nil
+// RequestPreview 请求预览，见 PreviewRequest
+type RequestPreview struct {
+	Method string // 请求方法
+	URL    string // 网关地址
+	Form   string // 签名后的请求表单
 }
 
-func (c *Client) VerifyHTML(body []byte) (V, error) {
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+// PreviewRequest 预览 Do 将发送的请求（签名后的表单及网关地址），不发送请求；
+// 可用于调试或向平台技术支持提供请求报文，签名逻辑与 Do 一致
+func (c *Client) PreviewRequest(service string, bizData V) (*RequestPreview, error) {
+	form, err := c.reqForm(service, bizData)
 	if err != nil {
 		return nil, err
 	}
 
-	content, ok := doc.Find("meta[name='MobilePayPlatform']").Attr("content")
-	if !ok || len(content) == 0 {
-		return nil, errors.New("err empty meta content")
+	preview := &RequestPreview{
+		Method: http.MethodPost,
+		URL:    c.gateway,
+		Form:   form,
 	}
 
-	vals, err := url.ParseQuery(content)
+	return preview, nil
+}
+
+// reqForm 生成 Do 的请求体
+func (c *Client) reqForm(service string, bizData V) (string, error) {
+	data, err := c.signData(service, bizData)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return c.VerifyQuery(vals)
+	return data.Encode("=", "&", WithEmptyMode(EmptyIgnore)), nil
 }
 
-func (c *Client) VerifyQuery(vals url.Values) (V, error) {
-	if c.pubKey == nil {
-		return nil, errors.New("public key is nil (forgotten configure?)")
+// signData 添加公共参数并签名，返回签名后的数据
+func (c *Client) signData(service string, bizData V) (V, error) {
+	if len(c.mchID) == 0 {
+		return nil, ErrNoMerchantID
 	}
 
-	ret := V{}
-	for k, vs := range vals {
-		if len(vs) != 0 {
-			ret.Set(k, vs[0])
+	// 拷贝一份，避免修改调用方的数据
+	data := bizData.Clone()
+
+	// 公共业务参数，不覆盖请求中已设置的值
+	for k, v := range c.defaultBiz {
+		if !data.Has(k) {
+			data.Set(k, v)
 		}
 	}
 
-	signStr := ret.Encode("=", "&", WithIgnoreKeys("sign", "sign_type"))
+	// 转码需在加密和签名之前
+	if c.reqEncoding != nil {
+		enc := c.reqEncoding.encoding().NewEncoder()
 
-	if err := c.pubKey.Verify(crypto.SHA256, []byte(signStr), []byte(ret["sign"])); err != nil {
-		return nil, err
+		for k, v := range data {
+			if isASCII(v) {
+				continue
+			}
+
+			b, err := enc.String(v)
+			if err != nil {
+				return nil, fmt.Errorf("encode field %q: %w", k, err)
+			}
+
+			data.Set(k, b)
+		}
 	}
 
-	return ret, nil
-}
+	// 先加密敏感字段，再对加密后的值签名（网关按密文验签）
+	for _, k := range c.encFields {
+		v := data.Get(k)
+		if len(v) == 0 {
+			continue
+		}
 
-// ReplyHTML 通知相应
-func (c *Client) ReplyHTML(data V) (string, error) {
-	if c.prvKey == nil {
-		return "", errors.New("private key is nil (forgotten configure?)")
+		cipher, err := c.Encrypt(v)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt field %q: %w", k, err)
+		}
+
+		data.Set(k, cipher)
 	}
 
+	data.Set("service", service)
+	data.Set("charset", "UTF-8")
+	data.Set("sign_type", c.signer.SignType())
+	data.Set("res_format", c.resFormat)
+	data.Set("version", c.version)
 	data.Set("mer_id", c.mchID)
-	data.Set("sign_type", "RSA")
-	data.Set("version", "4.0")
+
+	if err := c.setReplayFields(data); err != nil {
+		return nil, err
+	}
 
 	signStr := data.Encode("=", "&", WithEmptyMode(EmptyIgnore), WithIgnoreKeys("sign", "sign_type"))
+	c.logSignStr("sign", signStr)
 
-	sign, err := c.prvKey.Sign(crypto.SHA256, []byte(signStr))
+	sign, err := c.signer.Sign([]byte(signStr))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	data.Set("sign", base64.StdEncoding.EncodeToString(sign))
+	data.Set("sign", sign)
 
-	html := fmt.Sprintf(`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN"><html><head><META NAME="MobilePayPlatform" CONTENT="%s"/></head><body></body></html>`, data.Encode("=", "&", WithEmptyMode(EmptyIgnore)))
-
-	return html, nil
+	return data, nil
 }
 
-// Option 自定义设置项
-type Option func(c *Client)
+func (c *Client) VerifyHTML(body []byte) (V, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
 
-// WithHttpCli 设置自定义 HTTP Client
-func WithHttpCli(cli *http.Client) Option {
-	return func(c *Client) {
-		c.httpCli = NewHTTPClient(cli)
+	meta := doc.Find(fmt.Sprintf("meta[name=%q]", c.metaName))
+	if meta.Length() == 0 {
+		return nil, &metaNotFoundError{name: c.metaName, body: body}
 	}
-}
 
-// WithPrivateKey 设置商户RSA私钥
-func WithPrivateKey(key *PrivateKey) Option {
-	return func(c *Client) {
-		c.prvKey = key
+	content, ok := meta.Attr("content")
+	if !ok || len(content) == 0 {
+		return nil, errors.New("err empty meta content")
+	}
+
+	vals, err := url.ParseQuery(content)
+	if err != nil {
+		return nil, err
 	}
+
+	return c.verifyValues(vals)
 }
 
-// WithPublicKey 设置平台RSA公钥
-func WithPublicKey(key *PublicKey) Option {
-	return func(c *Client) {
-		c.pubKey = key
+// VerifyForm 表单格式（application/x-www-form-urlencoded）的报文验签，如：未使用HTML报文的通知回调；
+// 同 VerifyQuery，若设置了 WithNotifyTimestampTolerance，则同时校验 time_stamp
+func (c *Client) VerifyForm(body []byte) (V, error) {
+	vals, err := url.ParseQuery(string(bytes.TrimSpace(body)))
+	if err != nil {
+		return nil, err
 	}
+
+	return c.VerifyQuery(vals)
 }
 
-// WithLogger 设置日志记录
-func WithLogger(f func(ctx context.Context, data map[string]string)) Option {
-	return func(c *Client) {
-		c.logger = f
+// VerifyQuery K-V 数据验签，如：通知回调的查询参数；存在重复的key（如：重复的 sign 字段）时返回错误；
+// 若设置了 WithNotifyTimestampTolerance，则同时校验通知的 time_stamp
+func (c *Client) VerifyQuery(vals url.Values) (V, error) {
+	ret, err := c.verifyValues(vals)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.checkNotifyTime(ret); err != nil {
+		return nil, err
 	}
+
+	return ret, nil
 }
 
-// NewClient 生成银盛支付客户端
-func NewClient(mchID string, options ...Option) *Client {
-	c := &Client{
-		gateway: "https://pay.soopay.net/spay/pay/payservice.do",
-		mchID:   mchID,
-		httpCli: NewDefaultHTTPClient(),
+// verifyValues K-V 数据验签，不校验通知的 time_stamp（同步返回的报文不含该字段）
+func (c *Client) verifyValues(vals url.Values) (V, error) {
+	ret, err := valuesToV(vals)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.verify(ret)
+}
+
+// VerifyQueryKeys 同 VerifyQuery，并按签名串中的顺序返回参与验签的key；
+// 可用于审计，发现网关新增但未参与验签的字段（如：空值字段在 EmptyIgnore 模式下不参与验签）
+func (c *Client) VerifyQueryKeys(vals url.Values) (V, []string, error) {
+	ret, err := valuesToV(vals)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err = c.verify(ret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]string, 0, len(ret))
+	for k, v := range ret {
+		if k == c.signKey || k == c.signTypeKey {
+			continue
+		}
+
+		if len(v) == 0 && c.verifyEmptyMode == EmptyIgnore {
+			continue
+		}
+
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return ret, keys, nil
+}
+
+// metaNotFoundError 报文中未找到 meta 标签，通常是网关返回了错误页面
+type metaNotFoundError struct {
+	name string
+	body []byte
+}
+
+func (e *metaNotFoundError) Error() string {
+	return fmt.Sprintf("meta %q not found, html = %q", e.name, htmlSnippet(e.body))
+}
+
+// maxHTMLSnippet 错误信息中HTML片段的最大长度
+const maxHTMLSnippet = 256
+
+// htmlSnippet 截取HTML片段，用于错误信息
+func htmlSnippet(body []byte) string {
+	return truncate(string(body), maxHTMLSnippet)
+}
+
+// truncate 截取字符串的前 n 个字节，避免截断多字节字符
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n] + "..."
+}
+
+// valuesToV 转换为 V；存在重复的key时返回错误，
+// 避免验签的值与实际使用的值不一致（如：伪造的通知中重复的 sign 或 amount）
+func valuesToV(vals url.Values) (V, error) {
+	ret := V{}
+	for k, vs := range vals {
+		if len(vs) > 1 {
+			return nil, fmt.Errorf("duplicate key %q", k)
+		}
+
+		if len(vs) != 0 {
+			ret.Set(k, vs[0])
+		}
+	}
+
+	return ret, nil
+}
+
+// VerifyJSON JSON格式返回报文验签，如：{"ret_code":"0000","sign":"xxx"}
+func (c *Client) VerifyJSON(body []byte) (V, error) {
+	var ret V
+	if err := json.Unmarshal(body, &ret); err != nil {
+		return nil, err
+	}
+
+	return c.verify(ret)
+}
+
+// VerifyXML XML格式返回报文验签，如：<xml><ret_code>0000</ret_code><sign>xxx</sign></xml>
+func (c *Client) VerifyXML(body []byte) (V, error) {
+	ret, err := xmlToV(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.verify(ret)
+}
+
+func (c *Client) verify(ret V) (V, error) {
+	if err := c.VerifySign(ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// VerifySign 校验数据的 sign 字段（sign、sign_type 不参与验签，字段名见 WithSignKeys，空值字段的处理见 WithVerifyEmptyMode）；
+// 可用于校验通过其它渠道获得的签名数据
+func (c *Client) VerifySign(data V) error {
+	_, err := c.verifySign(data)
+	return err
+}
+
+// VerifyQueryPublicKey 同 VerifyQuery，并返回验签通过的平台公钥（可通过 Fingerprint 区分），
+// 用于密钥轮换期间确认网关使用的公钥；非RSA签名时返回的公钥为 nil
+func (c *Client) VerifyQueryPublicKey(vals url.Values) (V, *PublicKey, error) {
+	ret, err := valuesToV(vals)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := c.verifySign(ret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ret, key, nil
+}
+
+// verifySign 验签，RSA签名时返回验签通过的平台公钥
+func (c *Client) verifySign(data V) (*PublicKey, error) {
+	// 根据数据的 sign_type 选择验签方式，未指定时使用请求的签名方式
+	signType := data.Get(c.signTypeKey)
+	if len(signType) == 0 || c.testMode {
+		signType = c.signer.SignType()
+	}
+
+	signer, ok := c.signers[signType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sign_type %q", signType)
+	}
+
+	signStr := data.Encode("=", "&", WithEmptyMode(c.verifyEmptyMode), WithIgnoreKeys(c.signKey, c.signTypeKey))
+	c.logSignStr("verify", signStr)
+
+	if s, ok := signer.(*rsaSigner); ok {
+		return s.verifyKey([]byte(signStr), data.Get(c.signKey))
+	}
+
+	return nil, signer.Verify([]byte(signStr), data.Get(c.signKey))
+}
+
+// logSignStr 若 Logger 实现了 SignStrLogger，则记录待签名串
+func (c *Client) logSignStr(action, signStr string) {
+	if l, ok := c.logger.(SignStrLogger); ok {
+		l.LogSignStr(action, signStr)
+	}
+}
+
+// ReplyHTML 通知相应
+func (c *Client) ReplyHTML(reply V) (string, error) {
+	if len(c.mchID) == 0 {
+		return "", ErrNoMerchantID
+	}
+
+	// 拷贝一份，避免修改调用方的数据
+	data := reply.Clone()
+
+	data.Set("mer_id", c.mchID)
+	data.Set("sign_type", c.signer.SignType())
+	data.Set("version", c.version)
+
+	signStr := data.Encode("=", "&", WithEmptyMode(EmptyIgnore), WithIgnoreKeys("sign", "sign_type"))
+	c.logSignStr("sign", signStr)
+
+	var (
+		sign string
+		err  error
+	)
+
+	// RSA签名时，通知应答使用单独的哈希算法（见 WithReplyHash）
+	if s, ok := c.signer.(*rsaSigner); ok {
+		sign, err = s.signHash(c.replyHash, []byte(signStr))
+	} else {
+		sign, err = c.signer.Sign([]byte(signStr))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	data.Set("sign", sign)
+
+	// K-V需url编码（如：签名中的「+」），属性值需HTML转义（如：双引号）
+	content := html.EscapeString(data.Encode("=", "&", WithEmptyMode(EmptyIgnore), WithKVEscape()))
+
+	return fmt.Sprintf(`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN"><html><head><META NAME="%s" CONTENT="%s"/></head><body></body></html>`, html.EscapeString(c.metaName), content), nil
+}
+
+// Option 自定义设置项
+type Option func(c *Client)
+
+// WithGateway 设置网关地址（默认：生产环境），如：平台提供的测试环境网关、自定义或私有部署的网关；优先于 WithEnvironment
+// 注意：网关地址须为合法的 http(s) 绝对地址，否则 panic
+func WithGateway(gateway string) Option {
+	u, err := url.Parse(gateway)
+	if err != nil {
+		panic(fmt.Errorf("invalid gateway url: %w", err))
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		panic(fmt.Errorf("invalid gateway url %q: must be an absolute http(s) url", gateway))
+	}
+
+	return func(c *Client) {
+		c.gateway = gateway
+	}
+}
+
+// WithHttpCli 设置自定义 HTTP Client
+// 注意：设置后，作用于默认 HTTP Client 的选项（如：WithTLSConfig）将不再生效
+func WithHttpCli(cli *http.Client) Option {
+	return func(c *Client) {
+		c.httpCli = NewHTTPClient(cli)
+	}
+}
+
+// WithHTTPMiddleware 设置HTTP客户端中间件（同时作用于默认及自定义的 HTTP Client），先设置的中间件在外层；
+// 中间件包装的是单次HTTP请求，重试时每次请求都会经过中间件
+func WithHTTPMiddleware(middlewares ...HTTPMiddleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// WithHTTPClient 设置自定义的 HTTPClient 实现（如：RecordingHTTPClient、ReplayHTTPClient）
+// 注意：设置后，作用于默认 HTTP Client 的选项（如：WithTLSConfig）将不再生效
+func WithHTTPClient(cli HTTPClient) Option {
+	return func(c *Client) {
+		c.httpCli = cli
+	}
+}
+
+// WithTLSConfig 设置默认 HTTP Client 的TLS配置（默认：最低 TLS 1.2）；
+// 未设置 MinVersion 时使用 TLS 1.2，可设置为 tls.VersionTLS13 以满足更严格的要求
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		cfg = cfg.Clone()
+		if cfg == nil {
+			cfg = new(tls.Config)
+		}
+
+		if cfg.MinVersion == 0 {
+			cfg.MinVersion = tls.VersionTLS12
+		}
+
+		c.transport.TLSClientConfig = cfg
+	}
+}
+
+// WithMaxIdleConns 设置默认 HTTP Client 的最大空闲连接数（默认：0，不限制）
+func WithMaxIdleConns(n int) Option {
+	return func(c *Client) {
+		c.transport.MaxIdleConns = n
+		c.poolOpts = append(c.poolOpts, "WithMaxIdleConns")
+	}
+}
+
+// WithMaxIdleConnsPerHost 设置默认 HTTP Client 每个Host的最大空闲连接数（默认：1000）
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(c *Client) {
+		c.transport.MaxIdleConnsPerHost = n
+		c.poolOpts = append(c.poolOpts, "WithMaxIdleConnsPerHost")
+	}
+}
+
+// WithMaxConnsPerHost 设置默认 HTTP Client 每个Host的最大连接数（默认：1000）
+func WithMaxConnsPerHost(n int) Option {
+	return func(c *Client) {
+		c.transport.MaxConnsPerHost = n
+		c.poolOpts = append(c.poolOpts, "WithMaxConnsPerHost")
+	}
+}
+
+// WithIdleConnTimeout 设置默认 HTTP Client 空闲连接的超时时间（默认：60s）
+func WithIdleConnTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.transport.IdleConnTimeout = d
+		c.poolOpts = append(c.poolOpts, "WithIdleConnTimeout")
+	}
+}
+
+// WithHTTPTimeout 设置默认 HTTP Client 的超时时间（http.Client.Timeout，默认不超时），无需通过 Context 设置超时；
+// 与 Context 的截止时间（含 WithDefaultTimeout）同时生效，先到者为准；
+// 注意：该超时作用于每次HTTP请求（重试时重新计时），且包含读取返回报文的时间，对账文件较大时可能导致下载中断
+func WithHTTPTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.httpTimeout = d
+		c.poolOpts = append(c.poolOpts, "WithHTTPTimeout")
+	}
+}
+
+// WithHTTPProxy 设置默认 HTTP Client 的代理（默认读取环境变量），支持 http、https 和 socks5 协议
+// 注意：代理地址不合法时 panic；若同时设置了 WithHttpCli，则以自定义的 HTTP Client 为准，该选项不生效
+func WithHTTPProxy(proxyURL string) Option {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		panic(fmt.Errorf("invalid proxy url: %w", err))
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		panic(fmt.Errorf("invalid proxy url %q: scheme must be http, https or socks5", proxyURL))
+	}
+
+	if len(u.Host) == 0 {
+		panic(fmt.Errorf("invalid proxy url %q: host is required", proxyURL))
+	}
+
+	return func(c *Client) {
+		c.transport.Proxy = http.ProxyURL(u)
+	}
+}
+
+// WithClientCert 设置默认 HTTP Client 的客户端证书，用于网关要求双向TLS认证的场景；
+// 证书可通过 LoadCertFromPfxFile 加载，可与 WithTLSConfig 同时使用
+func WithClientCert(certs ...tls.Certificate) Option {
+	return func(c *Client) {
+		c.clientCerts = append(c.clientCerts, certs...)
+	}
+}
+
+// WithInsecureSkipVerify 跳过服务端TLS证书校验（默认校验）
+// 注意：存在中间人攻击风险，请勿在生产环境使用
+func WithInsecureSkipVerify() Option {
+	return func(c *Client) {
+		if c.transport.TLSClientConfig == nil {
+			c.transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+
+		c.transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+}
+
+// WithPrivateKey 设置商户RSA私钥
+func WithPrivateKey(key *PrivateKey) Option {
+	return func(c *Client) {
+		c.prvKey = key
+	}
+}
+
+// WithPublicKey 设置平台RSA公钥
+func WithPublicKey(key *PublicKey) Option {
+	return WithPublicKeys(key)
+}
+
+// WithPublicKeys 设置多个平台RSA公钥，用于密钥轮换期间新旧公钥的平滑切换；
+// 验签时依次尝试，任一公钥验签通过即可（见 VerifyQueryPublicKey）；敏感数据加密使用第一个公钥
+func WithPublicKeys(keys ...*PublicKey) Option {
+	return func(c *Client) {
+		c.pubKeys = c.pubKeys[:0]
+
+		for _, k := range keys {
+			if k != nil {
+				c.pubKeys = append(c.pubKeys, k)
+			}
+		}
+
+		c.pubKey = nil
+		if len(c.pubKeys) != 0 {
+			c.pubKey = c.pubKeys[0]
+		}
+	}
+}
+
+// WithVersion 设置接口版本号（默认：4.0）
+func WithVersion(version string) Option {
+	return func(c *Client) {
+		c.version = version
+	}
+}
+
+// WithResFormat 设置返回报文格式（默认：HTML）；
+// HTML、JSON、XML 以外的格式，返回报文按 K-V 字符串（如：ret_code=0000&sign=xxx）解析并验签
+func WithResFormat(format string) Option {
+	return func(c *Client) {
+		c.resFormat = format
+	}
+}
+
+// WithSignType 设置签名方式（默认：RSA），如：NewMD5Signer；
+// 验签时根据返回数据的 sign_type 选择对应的签名方式（RSA 始终可用）
+func WithSignType(signer Signer) Option {
+	return func(c *Client) {
+		c.signer = signer
+	}
+}
+
+// WithTestMode 测试模式，用于对接模拟网关的集成测试；签名及验签均使用指定的 signer（如：NewNopSigner），
+// 忽略返回数据的 sign_type；注意：须通过 WithGateway 设置非生产环境网关，否则 NewClient 时 panic
+func WithTestMode(signer Signer) Option {
+	return func(c *Client) {
+		c.testMode = true
+		c.signer = signer
+	}
+}
+
+// WithSignHash 设置商户RSA签名的哈希算法（默认：SHA1）
+// 仅作用于请求报文签名（Do、BuildForm）；异步通知应答签名见 WithReplyHash
+func WithSignHash(hash crypto.Hash) Option {
+	return func(c *Client) {
+		c.signHash = hash
+	}
+}
+
+// WithReplyHash 设置通知应答（ReplyHTML）RSA签名的哈希算法（默认：SHA256）
+func WithReplyHash(hash crypto.Hash) Option {
+	return func(c *Client) {
+		c.replyHash = hash
+	}
+}
+
+// WithVerifyHash 设置平台RSA验签的哈希算法（默认：先 SHA256，失败后再尝试 SHA1）；
+// 按顺序依次尝试，任一验签成功即通过，指定单个算法时不再尝试其它算法；
+// 作用于：同步返回报文（VerifyHTML）和异步通知（VerifyQuery）的验签
+func WithVerifyHash(hashes ...crypto.Hash) Option {
+	return func(c *Client) {
+		if len(hashes) != 0 {
+			c.verifyHashes = hashes
+		}
+	}
+}
+
+// WithRetry 设置请求重试；maxAttempts 为最大请求次数（含首次请求），backoff 为首次重试的等待时间，之后按指数递增（上限 30s，含随机抖动）
+// 仅在连接错误或HTTP状态码为 429、5xx 时重试，重试等待会响应 Context 的取消和超时；
+// 注意：仅重试只读的查询请求（订单、退款、付款查询，余额查询及对账文件下载），其它查询服务可通过 WithRetryServices 添加
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retryMax = maxAttempts
+		c.retryBackoff = backoff
+	}
+}
+
+// WithRetryServices 添加允许重试的服务（见 WithRetry），如：通过 Do 请求的其它查询服务；
+// 注意：请勿添加会改变资金或订单状态的服务
+func WithRetryServices(services ...string) Option {
+	return func(c *Client) {
+		if c.retryServices == nil {
+			c.retryServices = make(map[string]struct{})
+		}
+
+		for _, v := range services {
+			c.retryServices[v] = struct{}{}
+		}
+	}
+}
+
+// WithRateLimit 设置请求限流（每秒请求数 rps，突发数 burst），超出限制时等待（响应 Context 的取消和超时）后再发送请求；
+// 限流器由使用同一 Client 的所有 goroutine 共享，重试的请求同样受限
+func WithRateLimit(rps, burst int) Option {
+	if rps <= 0 || burst <= 0 {
+		panic(fmt.Errorf("invalid rate limit: rps = %d, burst = %d, both must be greater than 0", rps, burst))
+	}
+
+	return func(c *Client) {
+		c.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+}
+
+// WithCircuitBreaker 设置熔断器：连续失败 threshold 次后打开，cooldown 内的请求直接返回 ErrCircuitOpen，
+// 之后放行一个探测请求，成功则恢复；每次实际发送至网关的请求（含重试）分别计入，仅连接错误和HTTP 5xx 计为失败，业务错误、Context 取消或超时及限流等待失败不计入；
+// 状态变化通过 Logger（见 WarnLogger）和 BreakerObserver 通知
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	if threshold <= 0 || cooldown <= 0 {
+		panic(fmt.Errorf("invalid circuit breaker: threshold = %d, cooldown = %s, both must be greater than 0", threshold, cooldown))
+	}
+
+	return func(c *Client) {
+		c.breaker = &breaker{
+			threshold: threshold,
+			cooldown:  cooldown,
+			onChange:  c.breakerChanged,
+			now:       c.now,
+		}
+	}
+}
+
+// WithMetaName 设置HTML报文中承载数据的 meta 标签名称（默认：MobilePayPlatform），用于 VerifyHTML 和 ReplyHTML
+func WithMetaName(name string) Option {
+	if len(name) == 0 {
+		panic(errors.New("meta name is required"))
+	}
+
+	return func(c *Client) {
+		c.metaName = name
+	}
+}
+
+// WithClock 设置时钟（默认：系统时钟），用于请求时间、账单日期、幂等及熔断的过期时间等；
+// 请求耗时的统计始终使用系统时钟
+func WithClock(clock Clock) Option {
+	if clock == nil {
+		panic(errors.New("clock is nil"))
+	}
+
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithSignKeys 设置验签时签名及签名方式的字段名（默认：sign、sign_type），仅作用于返回报文及异步通知的验签，请求签名的字段名不变；
+// 个别服务的通知中签名字段名与默认值不同（如：大小写不同）时，可为该服务单独创建 Client 并设置
+func WithSignKeys(signKey, signTypeKey string) Option {
+	if len(signKey) == 0 || len(signTypeKey) == 0 {
+		panic(errors.New("sign key and sign_type key are required"))
+	}
+
+	return func(c *Client) {
+		c.signKey = signKey
+		c.signTypeKey = signTypeKey
+	}
+}
+
+// WithEncryptionPadding 设置敏感数据加解密的填充方式（默认：PaddingPKCS1v15），作用于 Encrypt、Decrypt 及自动加密的字段
+func WithEncryptionPadding(padding PaddingType) Option {
+	return func(c *Client) {
+		c.encPadding = padding
+	}
+}
+
+// WithDefaultBizFields 设置每次请求的公共业务参数（如：terminal_id、store_id），参与签名；
+// 请求中已设置的参数（包括空值）不会被覆盖
+func WithDefaultBizFields(fields V) Option {
+	return func(c *Client) {
+		c.defaultBiz = fields.Clone()
+	}
+}
+
+// WithVerifyEmptyMode 设置验签时空值字段的处理方式（默认：EmptyDefault，空值参与验签）；
+// 网关返回的空值字段未参与签名时，可设置为 EmptyIgnore（与请求签名规则一致）
+func WithVerifyEmptyMode(mode VEmptyMode) Option {
+	return func(c *Client) {
+		c.verifyEmptyMode = mode
+	}
+}
+
+// WithResponseCharset 指定网关返回敏感数据的字符集（UTF-8 或 GBK），用于解密时无法自动识别字符集的场景
+func WithResponseCharset(charset string) Option {
+	return func(c *Client) {
+		c.respCharset = charset
+	}
+}
+
+// WithResponseEncoding 指定网关返回敏感数据的中文编码（默认：GBK），解密结果不是UTF-8时按该编码转码；
+// 若数据中包含GBK范围外的字符（如：生僻字姓名），请使用 EncodingGB18030（兼容GBK）
+func WithResponseEncoding(enc Encoding) Option {
+	return func(c *Client) {
+		c.respEncoding = enc
+	}
+}
+
+// WithRequestEncoding 请求参数的值按指定编码（如：EncodingGBK）转码后再加密和签名，适用于要求GBK参数的服务；
+// 注意：charset 参数仍为 UTF-8，无法转码的字符（如：GBK范围外的生僻字）返回错误
+func WithRequestEncoding(enc Encoding) Option {
+	return func(c *Client) {
+		c.reqEncoding = &enc
+	}
+}
+
+// WithDefaultTimeout 设置默认的请求超时时间，仅在 Context 未设置截止时间时生效（不会覆盖 Context 已有的截止时间）
+// 注意：超时仅作用于HTTP请求（含重试）及读取返回报文的过程
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithMaxResponseBytes 设置返回报文的最大长度（默认：4MB），超过时返回错误；不作用于对账文件下载
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxRespBytes = n
+		}
+	}
+}
+
+// WithUserAgent 设置请求的 User-Agent（默认：soopay-go/<version>）
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithRequestHeader 设置请求头，可覆盖默认的 Content-Type 和 User-Agent
+func WithRequestHeader(key string, vals ...string) Option {
+	return func(c *Client) {
+		if c.header == nil {
+			c.header = http.Header{}
+		}
+
+		c.header.Del(key)
+
+		for _, v := range vals {
+			c.header.Add(key, v)
+		}
+	}
+}
+
+// WithEncryptFields 设置需自动加密的敏感字段（如：卡号、证件号、手机号），需配置平台公钥；
+// 请求时先对这些字段的值加密，再对加密后的参数签名，值为空的字段不加密；
+// 注意：已自行加密的字段（如：Transfer 的收款账号和户名）请勿重复配置
+func WithEncryptFields(keys ...string) Option {
+	return func(c *Client) {
+		c.encFields = append(c.encFields, keys...)
+	}
+}
+
+// WithRequestIDHeader 设置请求ID的请求头名称（如：X-Request-ID），Do 请求时发送 Context 中的请求ID（默认：不发送）
+func WithRequestIDHeader(name string) Option {
+	return func(c *Client) {
+		c.reqIDHeader = name
+	}
+}
+
+// WithContextHeaders 从 Context 中提取指定的请求头（如：X-B3-TraceId）随请求发送，用于透传链路追踪等信息；
+// extract 返回 Context 中携带的请求头，仅 names 中的请求头会被发送，且优先于 WithRequestHeader 设置的同名请求头
+func WithContextHeaders(extract func(ctx context.Context) http.Header, names ...string) Option {
+	return func(c *Client) {
+		c.ctxHeaderFn = extract
+		c.ctxHeaders = make([]string, 0, len(names))
+
+		for _, v := range names {
+			c.ctxHeaders = append(c.ctxHeaders, http.CanonicalHeaderKey(v))
+		}
+	}
+}
+
+// WithStrictResponseValidation 严格校验返回报文（默认：不校验）；
+// 验签通过后，校验返回的 service 与请求一致（未返回视为不一致），若返回 version 则校验与请求一致
+func WithStrictResponseValidation() Option {
+	return func(c *Client) {
+		c.strictResp = true
+	}
+}
+
+// WithIdempotency 开启幂等保护（默认：不开启），作用于下单、关单、退款、付款及预授权等请求；
+// 相同的请求（服务及商户单号均相同，如：付款的 order_id、退款的 refund_no）在请求中或成功后的 ttl 内，不再发送，直接返回首次请求的结果；
+// 请求失败或返回业务错误时不缓存结果，可重试；并发的调用方各自遵循自身 Context 的取消，共享的请求不受影响，
+// 但总有截止时间（沿用首个调用方的截止时间，未设置时为 WithDefaultTimeout，均未设置时为 30s），超时后可重新请求；
+// 注意：仅在单个进程内尽力保证，分布式部署时需借助外部协调（如：分布式锁）
+func WithIdempotency(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.idem = newIdempotency(ttl, c.now, c.cacheable)
+	}
+}
+
+// cacheable 判断幂等保护是否缓存返回结果：仅缓存成功或「处理中」的结果
+func (c *Client) cacheable(resp *Response) bool {
+	if resp == nil || resp.Data == nil {
+		return false
+	}
+
+	code := resp.Data.Get("ret_code")
+
+	return code == OK || c.isPending(code)
+}
+
+// WithBatchConcurrency 设置批量查询（如：BatchQueryOrder）的并发数（默认：8）
+func WithBatchConcurrency(n int) Option {
+	return func(c *Client) {
+		c.batchConcurrency = n
+	}
+}
+
+// WithMetrics 设置请求指标（默认：不记录）
+func WithMetrics(m Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// WithLogger 设置日志记录
+func WithLogger(f func(ctx context.Context, data map[string]string)) Option {
+	return func(c *Client) {
+		if f == nil {
+			c.logger = nil
+			return
+		}
+
+		c.logger = MapLogger(f)
+	}
+}
+
+// WithLogRedactKeys 设置日志中需脱敏的key（如：card_id、identity_code），请求及返回报文中对应的值替换为 ***
+func WithLogRedactKeys(keys ...string) Option {
+	return func(c *Client) {
+		if len(keys) != 0 {
+			c.logRedact = newRedactor(keys)
+		}
+	}
+}
+
+// WithLogSampling 设置日志采样率（默认：1，全部记录），取值范围 [0, 1]；
+// 请求失败时总是记录，rate 为 0 时仅记录失败的请求
+func WithLogSampling(rate float64) Option {
+	return func(c *Client) {
+		c.logSample = rate
+	}
+}
+
+// WithStructuredLogger 设置结构化日志记录
+func WithStructuredLogger(l Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// NewClient 生成银盛支付客户端
+func NewClient(mchID string, options ...Option) *Client {
+	c := &Client{
+		mchID: mchID,
+
+		transport: newDefaultTransport(),
+
+		maxRespBytes: defaultMaxResponseBytes,
+		userAgent:    defaultUserAgent,
+		logSample:    1,
+		version:      defaultVersion,
+		resFormat:    ResFormatHTML,
+		metaName:     defaultMetaName,
+		signKey:      "sign",
+		signTypeKey:  "sign_type",
+		clock:        wallClock{},
+		pendingCodes: map[string]struct{}{codeProcessing: {}},
+
+		signHash:        crypto.SHA1,
+		replyHash:       crypto.SHA256,
+		verifyHashes:    []crypto.Hash{crypto.SHA256, crypto.SHA1},
+		verifyEmptyMode: EmptyDefault,
 	}
 
 	for _, f := range options {
 		f(c)
 	}
 
+	// WithGateway 优先于 WithEnvironment
+	if len(c.gateway) == 0 {
+		c.gateway = c.env.Gateway()
+	}
+
+	if c.testMode {
+		if c.signer == nil {
+			panic(errors.New("soopay: WithTestMode requires a non-nil Signer"))
+		}
+
+		if u, _ := url.Parse(c.gateway); u == nil || strings.EqualFold(u.Hostname(), prodGatewayHost) {
+			panic(errors.New("soopay: WithTestMode is not allowed with the production gateway, use WithGateway to set a mock gateway"))
+		}
+	}
+
+	rsa := &rsaSigner{c: c}
+
+	c.signers = map[string]Signer{rsa.SignType(): rsa}
+	if c.signer != nil {
+		c.signers[c.signer.SignType()] = c.signer
+	} else {
+		c.signer = rsa
+	}
+
+	if len(c.clientCerts) != 0 {
+		cfg := new(tls.Config)
+		if c.transport.TLSClientConfig != nil {
+			cfg = c.transport.TLSClientConfig.Clone()
+		}
+
+		cfg.Certificates = append(cfg.Certificates, c.clientCerts...)
+		c.transport.TLSClientConfig = cfg
+	}
+
+	if c.httpCli == nil {
+		c.httpCli = NewHTTPClient(&http.Client{
+			Transport: c.transport,
+			Timeout:   c.httpTimeout,
+		})
+	} else if len(c.poolOpts) != 0 {
+		c.logWarn(context.Background(), fmt.Sprintf("soopay: %s ignored, because a custom HTTP client is used", strings.Join(c.poolOpts, ", ")))
+	}
+
+	c.baseCli = c.httpCli
+
+	// 先设置的中间件在外层
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		c.httpCli = c.middlewares[i](c.httpCli)
+	}
+
 	return c
 }
@@ -0,0 +1,28 @@
+package soopay
+
+import "time"
+
+// Clock 时钟，用于获取当前时间；可通过 WithClock 注入固定时间，便于测试
+type Clock interface {
+	Now() time.Time
+}
+
+// wallClock 系统时钟
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+// ClockFunc 函数形式的 Clock
+type ClockFunc func() time.Time
+
+// Now 实现 Clock 接口
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// now 返回当前时间
+func (c *Client) now() time.Time {
+	return c.clock.Now()
+}
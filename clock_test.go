@@ -0,0 +1,52 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithClock(t *testing.T) {
+	now := time.Date(2023, 12, 1, 10, 30, 0, 0, gatewayLocation)
+
+	cli := newTestClient(t, WithCircuitBreaker(1, time.Minute), WithClock(ClockFunc(func() time.Time { return now })))
+
+	body, err := cli.ReplyHTML(V{"ret_code": codeOrderNotFound, "ret_msg": "订单不存在"})
+	assert.Nil(t, err)
+
+	var form V
+
+	cli.httpCli = &mockHTTPClient{
+		fn: func(n int, b []byte) (*http.Response, error) {
+			form, _ = ParseV(string(b))
+			return mockResponse(http.StatusOK, body), nil
+		},
+	}
+
+	assert.Nil(t, cli.Ping(context.Background()))
+	assert.Equal(t, "PING20231201103000", form.Get("order_id"))
+
+	// 转账日期默认为当天
+	v, err := (&TransferRequest{AccountNO: "6222020000000000000", AccountName: "张三"}).bizData(cli.Encrypt, cli.now())
+	assert.Nil(t, err)
+	assert.Equal(t, "20231201", v.Get("mer_date"))
+
+	// 熔断冷却时间按注入的时钟计算
+	cli.httpCli = &mockHTTPClient{
+		fn: func(n int, b []byte) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	assert.NotNil(t, cli.Ping(context.Background()))
+	assert.True(t, errors.Is(cli.Ping(context.Background()), ErrCircuitOpen))
+
+	now = now.Add(time.Minute)
+	assert.False(t, errors.Is(cli.Ping(context.Background()), ErrCircuitOpen))
+
+	assert.Panics(t, func() { WithClock(nil) })
+}
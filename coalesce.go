@@ -0,0 +1,42 @@
+package soopay
+
+import (
+	"context"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// WithQueryCoalescing 合并并发的相同查询：同一时刻对同一订单（服务 + 订单号）的多次查询只向网关发送一次请求，结果由所有调用方共享
+// 注意：仅作用于只读的查询（QueryOrder、RefundQuery）；共享的请求不随任一调用方取消，但总有截止时间
+// （沿用首个调用方的截止时间，未设置时为 WithDefaultTimeout，均未设置时为 30s），各调用方在自身 Context 结束时提前返回
+func WithQueryCoalescing() Option {
+	return func(c *Client) {
+		c.queryGroup = new(singleflight.Group)
+	}
+}
+
+// doQuery 发送查询请求；启用 WithQueryCoalescing 后合并并发的相同查询
+func (c *Client) doQuery(ctx context.Context, service, key string, bizData V) (V, error) {
+	if c.queryGroup == nil {
+		return c.Do(ctx, service, bizData)
+	}
+
+	ch := c.queryGroup.DoChan(service+":"+key, func() (interface{}, error) {
+		reqCtx, cancel := c.sharedContext(ctx)
+		defer cancel()
+
+		return c.Do(reqCtx, service, bizData)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case ret := <-ch:
+		if ret.Err != nil {
+			return nil, ret.Err
+		}
+
+		// 共享的结果只读，拷贝一份避免调用方相互影响
+		return ret.Val.(V).Clone(), nil
+	}
+}
@@ -0,0 +1,174 @@
+package soopay
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithQueryCoalescing(t *testing.T) {
+	gateway := newTestClient(t)
+
+	body, err := gateway.ReplyHTML(V{"ret_code": OK, "order_id": "202312010001", "trade_state": "TRADE_SUCCESS", "amount": "100"})
+	assert.Nil(t, err)
+
+	var calls int32
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	cli := newTestClient(t, WithQueryCoalescing(), WithHTTPClient(HTTPClientFunc(func(ctx context.Context, method, reqURL string, b []byte, options ...HTTPOption) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+
+		return mockResponse(http.StatusOK, body), nil
+	})))
+
+	var wg sync.WaitGroup
+
+	query := func() {
+		defer wg.Done()
+
+		status, err := cli.QueryOrder(context.Background(), "202312010001")
+		assert.Nil(t, err)
+		assert.Equal(t, TradeSuccess, status.Status)
+	}
+
+	wg.Add(1)
+	go query()
+	<-started
+
+	for i := 0; i < 9; i++ {
+		wg.Add(1)
+		go query()
+	}
+
+	// 等待其余的查询加入首个请求
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 请求结束后，新的查询重新发送请求
+	_, err = cli.QueryOrder(context.Background(), "202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestWithQueryCoalescingContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	started := make(chan struct{})
+
+	cli := newTestClient(t, WithQueryCoalescing(), WithHTTPClient(HTTPClientFunc(func(ctx context.Context, method, reqURL string, b []byte, options ...HTTPOption) (*http.Response, error) {
+		close(started)
+		<-release
+
+		return mockResponse(http.StatusOK, ""), nil
+	})))
+
+	go cli.QueryOrder(context.Background(), "202312010001")
+	<-started
+
+	// 等待中的调用方在自身 Context 结束时提前返回
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := cli.QueryOrder(ctx, "202312010001")
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestWithQueryCoalescingLeaderCanceled(t *testing.T) {
+	gateway := newTestClient(t)
+
+	body, err := gateway.ReplyHTML(V{"ret_code": OK, "order_id": "202312010001", "trade_state": "TRADE_SUCCESS", "amount": "100"})
+	assert.Nil(t, err)
+
+	var calls int32
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	cli := newTestClient(t, WithQueryCoalescing(), WithHTTPClient(HTTPClientFunc(func(ctx context.Context, method, reqURL string, b []byte, options ...HTTPOption) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		close(started)
+
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		return mockResponse(http.StatusOK, body), nil
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := cli.QueryOrder(ctx, "202312010001")
+		first <- err
+	}()
+	<-started
+
+	second := make(chan *OrderStatus, 1)
+	go func() {
+		status, err := cli.QueryOrder(context.Background(), "202312010001")
+		assert.Nil(t, err)
+		second <- status
+	}()
+
+	// 等待第二个调用方加入共享的请求
+	time.Sleep(50 * time.Millisecond)
+
+	// 首个调用方取消，不影响其它调用方
+	cancel()
+	assert.Equal(t, context.Canceled, <-first)
+
+	close(release)
+
+	status := <-second
+	assert.NotNil(t, status)
+	assert.Equal(t, TradeSuccess, status.Status)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestWithQueryCoalescingHang(t *testing.T) {
+	timeout := sharedCallTimeout
+	sharedCallTimeout = 50 * time.Millisecond
+	defer func() { sharedCallTimeout = timeout }()
+
+	gateway := newTestClient(t)
+
+	body, err := gateway.ReplyHTML(V{"ret_code": OK, "order_id": "202312010001", "trade_state": "TRADE_SUCCESS", "amount": "100"})
+	assert.Nil(t, err)
+
+	var calls int32
+
+	// 首次请求网关无响应
+	cli := newTestClient(t, WithQueryCoalescing(), WithHTTPClient(HTTPClientFunc(func(ctx context.Context, method, reqURL string, b []byte, options ...HTTPOption) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		return mockResponse(http.StatusOK, body), nil
+	})))
+
+	_, err = cli.QueryOrder(context.Background(), "202312010001")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// 共享的请求超时后，后续查询重新发送请求
+	status, err := cli.QueryOrder(context.Background(), "202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, TradeSuccess, status.Status)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,54 @@
+package soopay
+
+import (
+	"context"
+	"time"
+)
+
+// detachedContext 保留 Context 中的值（如：请求ID、链路追踪信息），但不继承取消及截止时间；
+// 同 Go 1.21 的 context.WithoutCancel
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// withoutCancel 返回不随 ctx 取消的 Context，用于多个调用方共享的请求，避免首个调用方取消时影响其它调用方
+func withoutCancel(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+// sharedCallTimeout 共享请求（见 WithIdempotency、WithQueryCoalescing）在调用方及 WithDefaultTimeout 均未设置超时时的超时时间，
+// 避免网关无响应时，同一订单的后续请求一直等待
+var sharedCallTimeout = 30 * time.Second
+
+// sharedContext 返回共享请求使用的 Context：不随调用方取消，但总有截止时间；
+// 优先沿用调用方的截止时间，其次为 WithDefaultTimeout，最后为 sharedCallTimeout
+func (c *Client) sharedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := withoutCancel(ctx)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = sharedCallTimeout
+	}
+
+	return context.WithTimeout(detached, timeout)
+}
@@ -4,12 +4,15 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // RSAPadding RSA PEM 填充模式
@@ -20,6 +23,14 @@ const (
 	RSA_PKCS8 RSAPadding = 8 // PKCS#8 (格式：`PRIVATE KEY` 和 `PUBLIC KEY`)
 )
 
+// PaddingType RSA加解密的填充方式
+type PaddingType int
+
+const (
+	PaddingPKCS1v15   PaddingType = iota // PKCS#1 v1.5（默认）
+	PaddingOAEPSHA256                    // PKCS#1 OAEP（SHA-256）
+)
+
 // PrivateKey RSA私钥
 type PrivateKey struct {
 	key *rsa.PrivateKey
@@ -51,6 +62,11 @@ func (pk *PrivateKey) Sign(hash crypto.Hash, data []byte) ([]byte, error) {
 	return rsa.SignPKCS1v15(rand.Reader, pk.key, hash, h.Sum(nil))
 }
 
+// PublicFingerprint 对应公钥的指纹，同 PublicKey.Fingerprint；可用于确认私钥与平台登记的公钥是否匹配
+func (pk *PrivateKey) PublicFingerprint() string {
+	return fingerprint(&pk.key.PublicKey)
+}
+
 // NewPrivateKeyFromPemBlock 通过PEM字节生成RSA私钥
 func NewPrivateKeyFromPemBlock(padding RSAPadding, pemBlock []byte) (*PrivateKey, error) {
 	block, _ := pem.Decode(pemBlock)
@@ -68,13 +84,62 @@ func NewPrivateKeyFromPemBlock(padding RSAPadding, pemBlock []byte) (*PrivateKey
 		pk, err = x509.ParsePKCS1PrivateKey(block.Bytes)
 	case RSA_PKCS8:
 		pk, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported RSA padding %d", padding)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &PrivateKey{key: pk.(*rsa.PrivateKey)}, nil
+	key, ok := pk.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected RSA key, got %T", pk)
+	}
+
+	return &PrivateKey{key: key}, nil
+}
+
+// NewPrivateKeyFromPem 通过PEM字节生成RSA私钥，自动识别 PKCS#1 和 PKCS#8 格式
+// 适用于从配置中心或密钥管理服务中读取的PEM字符串
+func NewPrivateKeyFromPem(pemData []byte) (*PrivateKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("no PEM data is found")
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY", "PUBLIC KEY":
+		return nil, fmt.Errorf("PEM type is %q, got a public key instead of a private key", block.Type)
+	case "CERTIFICATE":
+		return nil, fmt.Errorf("PEM type is %q, got a certificate instead of a private key", block.Type)
+	}
+
+	key, err := parseRSAPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivateKey{key: key}, nil
+}
+
+// parseRSAPrivateKey 依次尝试 PKCS#1 和 PKCS#8 格式解析RSA私钥
+func parseRSAPrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	pk, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key (tried PKCS#1 and PKCS#8): %w", err)
+	}
+
+	key, ok := pk.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected RSA key, got %T", pk)
+	}
+
+	return key, nil
 }
 
 // NewPrivateKeyFromPemFile  通过PEM文件生成RSA私钥
@@ -93,14 +158,18 @@ func NewPrivateKeyFromPemFile(padding RSAPadding, pemFile string) (*PrivateKey,
 }
 
 // NewPrivateKeyFromPfxFile 通过pfx(p12)证书生成RSA私钥
-// 注意：证书需采用「TripleDES-SHA1」加密方式
 func NewPrivateKeyFromPfxFile(pfxFile, password string) (*PrivateKey, error) {
 	cert, err := LoadCertFromPfxFile(pfxFile, password)
 	if err != nil {
 		return nil, err
 	}
 
-	return &PrivateKey{key: cert.PrivateKey.(*rsa.PrivateKey)}, nil
+	key, ok := cert.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected RSA key, got %T", cert.PrivateKey)
+	}
+
+	return &PrivateKey{key: key}, nil
 }
 
 // PublicKey RSA公钥
@@ -110,6 +179,11 @@ type PublicKey struct {
 
 // Encrypt RSA公钥 PKCS#1 v1.5 加密
 func (pk *PublicKey) Encrypt(plainText []byte) ([]byte, error) {
+	// PKCS#1 v1.5 填充占用11字节
+	if limit := pk.key.Size() - 11; len(plainText) > limit {
+		return nil, pk.tooLong(len(plainText), limit)
+	}
+
 	return rsa.EncryptPKCS1v15(rand.Reader, pk.key, plainText)
 }
 
@@ -119,9 +193,21 @@ func (pk *PublicKey) EncryptOAEP(hash crypto.Hash, plainText []byte) ([]byte, er
 		return nil, fmt.Errorf("crypto: requested hash function (%s) is unavailable", hash.String())
 	}
 
+	// OAEP 填充占用 2*hLen+2 字节
+	if limit := pk.key.Size() - 2*hash.Size() - 2; len(plainText) > limit {
+		return nil, pk.tooLong(len(plainText), limit)
+	}
+
 	return rsa.EncryptOAEP(hash.New(), rand.Reader, pk.key, plainText, nil)
 }
 
+// ErrPlaintextTooLong 明文超过RSA公钥单次加密的最大长度（由密钥长度及填充方式决定）
+var ErrPlaintextTooLong = errors.New("plaintext too long for key")
+
+func (pk *PublicKey) tooLong(size, limit int) error {
+	return fmt.Errorf("%w: %d bytes, the maximum is %d bytes for a %d-bit key", ErrPlaintextTooLong, size, limit, pk.key.N.BitLen())
+}
+
 // Verify RSA公钥验签
 func (pk *PublicKey) Verify(hash crypto.Hash, data, signature []byte) error {
 	if !hash.Available() {
@@ -134,6 +220,23 @@ func (pk *PublicKey) Verify(hash crypto.Hash, data, signature []byte) error {
 	return rsa.VerifyPKCS1v15(pk.key, hash, h.Sum(nil), signature)
 }
 
+// Fingerprint 公钥指纹（DER编码的 PKIX 公钥的 SHA-256 哈希，小写十六进制），与密钥的PEM格式无关；
+// 可在启动时记录，用于确认当前加载的密钥版本
+func (pk *PublicKey) Fingerprint() string {
+	return fingerprint(pk.key)
+}
+
+func fingerprint(key *rsa.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.Sum256(der)
+
+	return hex.EncodeToString(h[:])
+}
+
 // NewPublicKeyFromPemBlock 通过PEM字节生成RSA公钥
 func NewPublicKeyFromPemBlock(padding RSAPadding, pemBlock []byte) (*PublicKey, error) {
 	block, _ := pem.Decode(pemBlock)
@@ -151,13 +254,41 @@ func NewPublicKeyFromPemBlock(padding RSAPadding, pemBlock []byte) (*PublicKey,
 		pk, err = x509.ParsePKCS1PublicKey(block.Bytes)
 	case RSA_PKCS8:
 		pk, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported RSA padding %d", padding)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &PublicKey{key: pk.(*rsa.PublicKey)}, nil
+	key, ok := pk.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("expected RSA key, got %T", pk)
+	}
+
+	return &PublicKey{key: key}, nil
+}
+
+// NewPublicKeyFromPem 通过PEM字节生成RSA公钥，根据PEM类型自动识别 PKCS#1 和 PKCS#8 格式
+func NewPublicKeyFromPem(pemData []byte) (*PublicKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("no PEM data is found")
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return NewPublicKeyFromPemBlock(RSA_PKCS1, pemData)
+	case "PUBLIC KEY":
+		return NewPublicKeyFromPemBlock(RSA_PKCS8, pemData)
+	case "RSA PRIVATE KEY", "PRIVATE KEY":
+		return nil, fmt.Errorf("PEM type is %q, got a private key instead of a public key", block.Type)
+	case "CERTIFICATE":
+		return nil, fmt.Errorf("PEM type is %q, got a certificate (use NewPublicKeyFromDerBlock instead)", block.Type)
+	}
+
+	return nil, fmt.Errorf("unsupported PEM type %q for a public key", block.Type)
 }
 
 // NewPublicKeyFromPemFile 通过PEM文件生成RSA公钥
@@ -189,7 +320,70 @@ func NewPublicKeyFromDerBlock(pemBlock []byte) (*PublicKey, error) {
 		return nil, err
 	}
 
-	return &PublicKey{key: cert.PublicKey.(*rsa.PublicKey)}, nil
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("expected RSA key, got %T", cert.PublicKey)
+	}
+
+	return &PublicKey{key: key}, nil
+}
+
+// certOptions 证书解析选项
+type certOptions struct {
+	skipExpiry bool
+}
+
+// CertOption 证书解析选项
+type CertOption func(o *certOptions)
+
+// WithSkipExpiryCheck 不校验证书的有效期
+func WithSkipExpiryCheck() CertOption {
+	return func(o *certOptions) {
+		o.skipExpiry = true
+	}
+}
+
+// NewPublicKeyFromCert 通过X.509证书（PEM格式，也支持DER格式）提取平台RSA公钥，用于 WithPublicKey；
+// 证书已过期或尚未生效时返回错误，可通过 WithSkipExpiryCheck 跳过有效期校验
+func NewPublicKeyFromCert(pemData []byte, options ...CertOption) (*PublicKey, error) {
+	o := new(certOptions)
+	for _, f := range options {
+		f(o)
+	}
+
+	der := pemData
+
+	if block, _ := pem.Decode(pemData); block != nil {
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("PEM type is %q, expected CERTIFICATE", block.Type)
+		}
+
+		der = block.Bytes
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("expected RSA key, got %T", cert.PublicKey)
+	}
+
+	if !o.skipExpiry {
+		now := time.Now()
+
+		if now.After(cert.NotAfter) {
+			return nil, fmt.Errorf("certificate %q has expired at %s", cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339))
+		}
+
+		if now.Before(cert.NotBefore) {
+			return nil, fmt.Errorf("certificate %q is not valid until %s", cert.Subject.CommonName, cert.NotBefore.Format(time.RFC3339))
+		}
+	}
+
+	return &PublicKey{key: key}, nil
 }
 
 // NewPublicKeyFromDerFile 通过DER证书生成RSA公钥
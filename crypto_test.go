@@ -2,8 +2,20 @@ package soopay
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -125,3 +137,173 @@ Vv8zI7kt+uZxD5mBGglKs2wzaHqADBXa5kSznIvkcZSg07UQQYU6
 	assert.Equal(t, "er5a6N6dQMkCKxIKLUrIcQYNsUAEhy+e0YIFbFF4lG2+IwgXBwe3StZOUvh1vPXbSu/dr/lGCDXTrqzRoWQyeyEZ5T8qmDHENXNMySCq9FJrrGLORnJlmKgg48UEJfGvgCLqdZudPZUHbmDgxm7bkqtDZEV4gHgr5zdRVoJJdDqsH1CfFQMFdoCLXybTmUHuQSZ20Qpdd79GXScMITdqTccYGHINTWtXTSPvBmWLxY7C7YaMQ6HJbshstHbGXOP0uSio6+a4pVoZmMd1F2knZL63Ew5/y5A8vjXYeC5W+1F3KY9Pd6ne3SdCvDzSpYFTsks4lrwCERd2MwxS8uXqfg==", base64.StdEncoding.EncodeToString(sign2))
 	assert.Nil(t, pubKey.Verify(crypto.SHA256, []byte(data), sign2))
 }
+
+func TestNewKeyFromPem(t *testing.T) {
+	prvKey, err := NewPrivateKeyFromPem(testPrivateKey)
+	assert.Nil(t, err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(prvKey.key)
+	assert.Nil(t, err)
+
+	prvKey8, err := NewPrivateKeyFromPem(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+	assert.Nil(t, err)
+	assert.True(t, prvKey.key.Equal(prvKey8.key))
+
+	_, err = NewPrivateKeyFromPem(testPublicKey)
+	assert.EqualError(t, err, `PEM type is "RSA PUBLIC KEY", got a public key instead of a private key`)
+
+	pubKey, err := NewPublicKeyFromPem(testPublicKey)
+	assert.Nil(t, err)
+	assert.True(t, pubKey.key.Equal(&prvKey.key.PublicKey))
+
+	pkix, err := x509.MarshalPKIXPublicKey(pubKey.key)
+	assert.Nil(t, err)
+
+	pubKey8, err := NewPublicKeyFromPem(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+	assert.Nil(t, err)
+	assert.True(t, pubKey.key.Equal(pubKey8.key))
+
+	_, err = NewPublicKeyFromPem(testPrivateKey)
+	assert.EqualError(t, err, `PEM type is "RSA PRIVATE KEY", got a private key instead of a public key`)
+}
+
+func TestParseRSAPrivateKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	assert.Nil(t, err)
+
+	ecPem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	_, err = NewPrivateKeyFromPem(ecPem)
+	assert.EqualError(t, err, "expected RSA key, got *ecdsa.PrivateKey")
+
+	_, err = NewPrivateKeyFromPemBlock(RSA_PKCS8, ecPem)
+	assert.EqualError(t, err, "expected RSA key, got *ecdsa.PrivateKey")
+
+	// PEM类型与实际格式不符时自动回退
+	block, _ := pem.Decode(testPrivateKey)
+
+	_, err = NewPrivateKeyFromPem(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: block.Bytes}))
+	assert.Nil(t, err)
+
+	_, err = NewPrivateKeyFromPem(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("invalid")}))
+	assert.ErrorContains(t, err, "tried PKCS#1 and PKCS#8")
+}
+
+func TestNewPublicKeyFromCert(t *testing.T) {
+	prvKey, err := NewPrivateKeyFromPem(testPrivateKey)
+	assert.Nil(t, err)
+
+	newCert := func(pub, priv any, notBefore, notAfter time.Time) []byte {
+		tpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "soopay"},
+			NotBefore:    notBefore,
+			NotAfter:     notAfter,
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, pub, priv)
+		assert.Nil(t, err)
+
+		return der
+	}
+
+	now := time.Now()
+
+	der := newCert(&prvKey.key.PublicKey, prvKey.key, now.Add(-time.Hour), now.Add(time.Hour))
+
+	pubKey, err := NewPublicKeyFromCert(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	assert.Nil(t, err)
+	assert.True(t, pubKey.key.Equal(&prvKey.key.PublicKey))
+
+	// DER格式
+	pubKey, err = NewPublicKeyFromCert(der)
+	assert.Nil(t, err)
+	assert.True(t, pubKey.key.Equal(&prvKey.key.PublicKey))
+
+	// 证书已过期
+	expired := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: newCert(&prvKey.key.PublicKey, prvKey.key, now.Add(-2*time.Hour), now.Add(-time.Hour))})
+
+	_, err = NewPublicKeyFromCert(expired)
+	assert.ErrorContains(t, err, `certificate "soopay" has expired at`)
+
+	_, err = NewPublicKeyFromCert(expired, WithSkipExpiryCheck())
+	assert.Nil(t, err)
+
+	// 非RSA证书
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	_, err = NewPublicKeyFromCert(newCert(&ecKey.PublicKey, ecKey, now.Add(-time.Hour), now.Add(time.Hour)))
+	assert.EqualError(t, err, "expected RSA key, got *ecdsa.PublicKey")
+
+	_, err = NewPublicKeyFromCert(testPublicKey)
+	assert.EqualError(t, err, `PEM type is "RSA PUBLIC KEY", expected CERTIFICATE`)
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	prvKey, err := NewPrivateKeyFromPem(testPrivateKey)
+	assert.Nil(t, err)
+
+	pubKey, err := NewPublicKeyFromPem(testPublicKey)
+	assert.Nil(t, err)
+
+	fp := pubKey.Fingerprint()
+	assert.Len(t, fp, 64)
+	assert.Equal(t, fp, prvKey.PublicFingerprint())
+
+	// 与PEM格式无关
+	pkix, err := x509.MarshalPKIXPublicKey(pubKey.key)
+	assert.Nil(t, err)
+
+	pubKey8, err := NewPublicKeyFromPem(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+	assert.Nil(t, err)
+	assert.Equal(t, fp, pubKey8.Fingerprint())
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Nil(t, err)
+	assert.NotEqual(t, fp, (&PublicKey{key: &otherKey.PublicKey}).Fingerprint())
+}
+
+func TestEncryptTooLong(t *testing.T) {
+	pubKey, err := NewPublicKeyFromPem(testPublicKey)
+	assert.Nil(t, err)
+
+	limit := pubKey.key.Size() - 11
+
+	_, err = pubKey.Encrypt(make([]byte, limit))
+	assert.Nil(t, err)
+
+	_, err = pubKey.Encrypt(make([]byte, limit+1))
+	assert.True(t, errors.Is(err, ErrPlaintextTooLong))
+	assert.EqualError(t, err, fmt.Sprintf("plaintext too long for key: %d bytes, the maximum is %d bytes for a %d-bit key", limit+1, limit, pubKey.key.N.BitLen()))
+
+	_, err = pubKey.EncryptOAEP(crypto.SHA256, make([]byte, pubKey.key.Size()-2*32-1))
+	assert.True(t, errors.Is(err, ErrPlaintextTooLong))
+
+	cli := newTestClient(t)
+
+	_, err = cli.Encrypt(strings.Repeat("a", limit+1))
+	assert.True(t, errors.Is(err, ErrPlaintextTooLong))
+}
+
+func TestWithEncryptionPadding(t *testing.T) {
+	for _, padding := range []PaddingType{PaddingPKCS1v15, PaddingOAEPSHA256} {
+		cli := newTestClient(t, WithEncryptionPadding(padding))
+
+		cipher, err := cli.Encrypt("6222020000000000000")
+		assert.Nil(t, err)
+
+		plain, err := cli.Decrypt(cipher)
+		assert.Nil(t, err)
+		assert.Equal(t, "6222020000000000000", plain)
+	}
+
+	// 加解密的填充方式不一致
+	cipher := newTestClient(t, WithEncryptionPadding(PaddingOAEPSHA256)).MustEncrypt("6222020000000000000")
+
+	_, err := newTestClient(t).Decrypt(cipher)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,34 @@
+package soopay
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Encoding 中文字符编码
+type Encoding int
+
+const (
+	EncodingGBK     Encoding = iota // GBK（默认）
+	EncodingGB18030                 // GB18030，兼容GBK，支持GBK范围外的字符（如：生僻字「𠀀」、部分符号）
+)
+
+func (e Encoding) encoding() encoding.Encoding {
+	if e == EncodingGB18030 {
+		return simplifiedchinese.GB18030
+	}
+
+	return simplifiedchinese.GBK
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,41 @@
+package soopay
+
+import "fmt"
+
+// Environment 网关环境
+//
+// 平台未公开测试环境的网关地址（由平台在商户接入时提供），因此不提供测试环境；
+// 对接测试环境时，通过 WithGateway 设置平台提供的网关地址，如：
+//
+//	soopay.NewClient(merchantID, soopay.WithGateway("https://<测试环境网关>/spay/pay/payservice.do"))
+type Environment int
+
+const (
+	Production Environment = iota // 生产环境（默认）
+)
+
+// String 返回环境名称
+func (e Environment) String() string {
+	if e == Production {
+		return "production"
+	}
+
+	return fmt.Sprintf("Environment(%d)", int(e))
+}
+
+// Gateway 返回环境对应的网关地址
+func (e Environment) Gateway() string {
+	return defaultGateway
+}
+
+// WithEnvironment 设置网关环境（默认：Production）；测试环境请使用 WithGateway
+// 注意：同时设置 WithGateway 时，以 WithGateway 为准（与选项顺序无关）；未知的环境 panic
+func WithEnvironment(env Environment) Option {
+	if env != Production {
+		panic(fmt.Errorf("invalid environment: %v", env))
+	}
+
+	return func(c *Client) {
+		c.env = env
+	}
+}
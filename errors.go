@@ -0,0 +1,102 @@
+package soopay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	// ErrNoPublicKey 未设置平台公钥（见 WithPublicKey），加密及RSA验签时返回
+	ErrNoPublicKey = errors.New("public key is nil (forgotten configure?)")
+
+	// ErrNoPrivateKey 未设置商户私钥（见 WithPrivateKey），RSA签名及解密时返回
+	ErrNoPrivateKey = errors.New("private key is nil (forgotten configure?)")
+
+	// ErrNoMerchantID 未设置商户编号（NewClient 的参数 mchID 为空），请求签名及通知应答时返回
+	ErrNoMerchantID = errors.New("merchant id is empty (forgotten configure?)")
+)
+
+// ResponseError 网关业务错误（返回码不为 OK）
+type ResponseError struct {
+	Code string // 网关返回码
+	Msg  string // 网关返回信息
+	Data V      // 网关返回的完整数据
+}
+
+// Error 实现 error 接口
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("ret_code = %s, ret_msg = %s", e.Code, e.Msg)
+}
+
+// Is 用于 errors.Is 判断；返回码相同即视为同一错误，
+// 若 target 未指定返回码，则匹配任意 ResponseError
+func (e *ResponseError) Is(target error) bool {
+	t, ok := target.(*ResponseError)
+	if !ok {
+		return false
+	}
+
+	return len(t.Code) == 0 || t.Code == e.Code
+}
+
+// checkRetCode 校验网关返回码，不为 OK 时返回 ResponseError
+func checkRetCode(data V) error {
+	if code := data.Get("ret_code"); code != OK {
+		return &ResponseError{
+			Code: code,
+			Msg:  data.Get("ret_msg"),
+			Data: data,
+		}
+	}
+
+	return nil
+}
+
+// VerifyError 返回报文解析或验签失败
+type VerifyError struct {
+	Err error
+}
+
+// Error 实现 error 接口
+func (e *VerifyError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap 用于 errors.Is 和 errors.As 判断
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// maxGatewayExcerpt GatewayError 中报文摘要的最大长度
+const maxGatewayExcerpt = 256
+
+// GatewayError 网关返回了不含签名数据的报文（如：过载时返回的HTML错误页面或纯文本错误）
+type GatewayError struct {
+	StatusCode int    // HTTP状态码
+	Body       string // 报文摘要（HTML报文仅保留文本内容）
+}
+
+// Error 实现 error 接口
+func (e *GatewayError) Error() string {
+	return fmt.Sprintf("gateway error, StatusCode = %d, body = %q", e.StatusCode, e.Body)
+}
+
+func newGatewayError(statusCode int, body []byte) *GatewayError {
+	text := string(body)
+
+	// HTML报文仅保留文本内容
+	if doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body)); err == nil {
+		if s := doc.Text(); len(strings.TrimSpace(s)) != 0 {
+			text = s
+		}
+	}
+
+	return &GatewayError{
+		StatusCode: statusCode,
+		Body:       truncate(strings.Join(strings.Fields(text), " "), maxGatewayExcerpt),
+	}
+}
@@ -0,0 +1,84 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseError(t *testing.T) {
+	assert.Nil(t, checkRetCode(V{"ret_code": OK}))
+
+	err := checkRetCode(V{"ret_code": codeOrderNotFound, "ret_msg": "订单不存在"})
+	assert.EqualError(t, err, "ret_code = 00060700, ret_msg = 订单不存在")
+	assert.True(t, errors.Is(err, ErrOrderNotFound))
+	assert.True(t, errors.Is(fmt.Errorf("query: %w", err), ErrOrderNotFound))
+	assert.True(t, errors.Is(err, &ResponseError{}))
+	assert.False(t, errors.Is(err, ErrOrderPaid))
+
+	var respErr *ResponseError
+	assert.True(t, errors.As(err, &respErr))
+	assert.Equal(t, "订单不存在", respErr.Data.Get("ret_msg"))
+}
+
+func TestGatewayError(t *testing.T) {
+	cli := newTestClient(t)
+
+	for body, excerpt := range map[string]string{
+		"<html>\n<head><title>503 Service Temporarily Unavailable</title></head>\n<body><h1>系统繁忙，请稍后再试</h1></body>\n</html>": "503 Service Temporarily Unavailable 系统繁忙，请稍后再试",
+		"system busy": "system busy",
+	} {
+		cli.httpCli = &mockHTTPClient{
+			fn: func(n int, b []byte) (*http.Response, error) {
+				return mockResponse(http.StatusOK, body), nil
+			},
+		}
+
+		_, err := cli.Do(context.Background(), serviceQueryOrder, V{"order_id": "202312010001"})
+
+		var gwErr *GatewayError
+		assert.True(t, errors.As(err, &gwErr))
+		assert.Equal(t, http.StatusOK, gwErr.StatusCode)
+		assert.Equal(t, excerpt, gwErr.Body)
+		assert.False(t, errors.As(err, new(*VerifyError)))
+	}
+}
+
+func TestKeyErrors(t *testing.T) {
+	cli := NewClient("10001")
+
+	_, err := cli.Encrypt("6222020000000000000")
+	assert.True(t, errors.Is(err, ErrNoPublicKey))
+
+	_, err = cli.Decrypt("xxx")
+	assert.True(t, errors.Is(err, ErrNoPrivateKey))
+
+	_, err = cli.BuildForm(serviceQueryOrder, V{"order_id": "202312010001"})
+	assert.True(t, errors.Is(err, ErrNoPrivateKey))
+
+	_, err = cli.ReplyHTML(V{"ret_code": OK})
+	assert.True(t, errors.Is(err, ErrNoPrivateKey))
+
+	_, err = cli.VerifyQuery(signedValues(t, newTestClient(t), serviceQueryOrder, V{"order_id": "202312010001"}))
+	assert.True(t, errors.Is(err, ErrNoPublicKey))
+
+	// 未设置商户编号
+	_, err = newTestClientWithID(t, "").BuildForm(serviceQueryOrder, V{"order_id": "202312010001"})
+	assert.True(t, errors.Is(err, ErrNoMerchantID))
+
+	_, err = newTestClientWithID(t, "").Do(context.Background(), serviceQueryOrder, V{"order_id": "202312010001"})
+	assert.True(t, errors.Is(err, ErrNoMerchantID))
+
+	_, err = newTestClientWithID(t, "").ReplyHTML(V{"ret_code": OK})
+	assert.True(t, errors.Is(err, ErrNoMerchantID))
+
+	// 加密字段时未设置公钥
+	cli = NewClient("10001", WithEncryptFields("card_id"))
+
+	_, err = cli.BuildForm(serviceQueryOrder, V{"card_id": "6222020000000000000"})
+	assert.True(t, errors.Is(err, ErrNoPublicKey))
+}
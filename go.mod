@@ -4,9 +4,12 @@ go 1.20
 
 require (
 	github.com/PuerkitoBio/goquery v1.8.1
-	github.com/qiniu/iconv v1.2.0
 	github.com/stretchr/testify v1.8.4
 	golang.org/x/crypto v0.16.0
+	golang.org/x/sync v0.5.0
+	golang.org/x/text v0.14.0
+	golang.org/x/time v0.5.0
+	software.sslmate.com/src/go-pkcs12 v0.4.0
 )
 
 require (
@@ -105,6 +105,11 @@ func (c *httpCli) Do(ctx context.Context, method, reqURL string, body []byte, op
 	return resp, nil
 }
 
+// CloseIdleConnections 关闭空闲连接
+func (c *httpCli) CloseIdleConnections() {
+	c.client.CloseIdleConnections()
+}
+
 // NewHTTPClient 通过官方 `http.Client` 生成一个HTTP客户端
 func NewHTTPClient(cli *http.Client) HTTPClient {
 	return &httpCli{
@@ -116,22 +121,24 @@ func NewHTTPClient(cli *http.Client) HTTPClient {
 func NewDefaultHTTPClient() HTTPClient {
 	return &httpCli{
 		client: &http.Client{
-			Transport: &http.Transport{
-				Proxy: http.ProxyFromEnvironment,
-				DialContext: (&net.Dialer{
-					Timeout:   30 * time.Second,
-					KeepAlive: 60 * time.Second,
-				}).DialContext,
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-				},
-				MaxIdleConns:          0,
-				MaxIdleConnsPerHost:   1000,
-				MaxConnsPerHost:       1000,
-				IdleConnTimeout:       60 * time.Second,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ExpectContinueTimeout: time.Second,
-			},
+			Transport: newDefaultTransport(),
 		},
 	}
 }
+
+func newDefaultTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 60 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       &tls.Config{MinVersion: tls.VersionTLS12},
+		MaxIdleConns:          0,
+		MaxIdleConnsPerHost:   1000,
+		MaxConnsPerHost:       1000,
+		IdleConnTimeout:       60 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
@@ -0,0 +1,145 @@
+package soopay
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// idempotentServices 启用幂等保护的服务（会改变资金或订单状态的请求）及其商户单号的字段名；
+// 查询类请求不做缓存，以免返回过期的状态
+var idempotentServices = map[string]string{
+	serviceUnifiedOrder: "order_id",
+	serviceCloseOrder:   "order_id",
+	serviceRefund:       "refund_no",
+	serviceTransfer:     "order_id",
+	serviceAuthFreeze:   "order_id",
+	serviceAuthCapture:  "auth_no",
+	serviceAuthCancel:   "auth_no",
+}
+
+type idemCall struct {
+	done   chan struct{}
+	resp   *Response
+	err    error
+	expire time.Time
+}
+
+// idempotency 进程内的幂等保护：相同的请求（服务 + 商户单号）在请求中或成功后的 ttl 内，直接返回首次请求的结果
+type idempotency struct {
+	ttl       time.Duration
+	now       func() time.Time
+	cacheable func(resp *Response) bool
+	mu        sync.Mutex
+	calls     map[string]*idemCall
+}
+
+func newIdempotency(ttl time.Duration, now func() time.Time, cacheable func(resp *Response) bool) *idempotency {
+	return &idempotency{
+		ttl:       ttl,
+		now:       now,
+		cacheable: cacheable,
+		calls:     make(map[string]*idemCall),
+	}
+}
+
+// key 幂等键（服务 + 商户单号）；未设置商户单号的请求不做幂等保护。
+// 注意：不能使用全部业务参数，部分请求含有随机填充的加密字段（如：Transfer 的收款账号），每次请求均不相同
+func (g *idempotency) key(service string, bizData V) (string, bool) {
+	field, ok := idempotentServices[service]
+	if !ok {
+		return "", false
+	}
+
+	id := bizData.Get(field)
+	if len(id) == 0 {
+		return "", false
+	}
+
+	return service + ":" + id, true
+}
+
+func (g *idempotency) do(ctx context.Context, key string, fn func() (*Response, error)) (*Response, error) {
+	now := g.now()
+
+	g.mu.Lock()
+
+	// 清理过期的结果
+	for k, v := range g.calls {
+		if !v.expire.IsZero() && now.After(v.expire) {
+			delete(g.calls, k)
+		}
+	}
+
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		return call.resp.clone(), call.err
+	}
+
+	call := &idemCall{done: make(chan struct{})}
+	g.calls[key] = call
+
+	g.mu.Unlock()
+
+	// 在独立的 goroutine 中请求，首个调用方取消时，请求仍继续完成，其它调用方不受影响
+	go func() {
+		resp, err := fn()
+
+		g.mu.Lock()
+		if err != nil || (g.cacheable != nil && !g.cacheable(resp)) {
+			// 请求失败或业务错误不缓存，允许重试
+			delete(g.calls, key)
+		} else {
+			call.expire = g.now().Add(g.ttl)
+		}
+		call.resp, call.err = resp, err
+		g.mu.Unlock()
+
+		close(call.done)
+	}()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return call.resp.clone(), call.err
+}
+
+// reset 清空已完成的结果，请求中的调用不受影响
+func (g *idempotency) reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for k, v := range g.calls {
+		if !v.expire.IsZero() {
+			delete(g.calls, k)
+		}
+	}
+}
+
+func (r *Response) clone() *Response {
+	if r == nil {
+		return nil
+	}
+
+	ret := &Response{
+		StatusCode: r.StatusCode,
+		Header:     r.Header.Clone(),
+		Body:       r.Body,
+		now:        r.now,
+	}
+	if r.Data != nil {
+		ret.Data = r.Data.Clone()
+	}
+
+	return ret
+}
@@ -0,0 +1,258 @@
+package soopay
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	cli := newTestClient(t, WithIdempotency(time.Minute))
+
+	body, err := cli.ReplyHTML(V{"ret_code": OK, "order_id": "202312010001", "trade_no": "3312010001"})
+	assert.Nil(t, err)
+
+	var calls int32
+
+	release := make(chan struct{})
+
+	cli.httpCli = httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return mockResponse(http.StatusOK, body), nil
+	})
+
+	req := &UnifiedOrderRequest{
+		OrderID:      "202312010001",
+		Amount:       100,
+		Subject:      "test",
+		NotifyURL:    "https://example.com/notify",
+		ScancodeType: "WECHAT",
+	}
+
+	// 并发的重复请求只发送一次
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			resp, err := cli.UnifiedOrder(context.Background(), req)
+			assert.Nil(t, err)
+			assert.Equal(t, "3312010001", resp.TradeNO)
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// ttl 内返回首次请求的结果
+	_, err = cli.UnifiedOrder(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 查询类请求不做缓存
+	_, err = cli.QueryOrder(context.Background(), "202312010001")
+	assert.Nil(t, err)
+	_, err = cli.QueryOrder(context.Background(), "202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyError(t *testing.T) {
+	g := newIdempotency(time.Minute, time.Now, nil)
+
+	var calls int
+
+	fn := func() (*Response, error) {
+		calls++
+		if calls == 1 {
+			return nil, context.DeadlineExceeded
+		}
+
+		return &Response{Data: V{"ret_code": OK}}, nil
+	}
+
+	key, ok := g.key(serviceRefund, V{"order_id": "202312010001", "refund_no": "R1"})
+	assert.True(t, ok)
+
+	key2, _ := g.key(serviceRefund, V{"order_id": "202312010001", "refund_no": "R2"})
+	assert.NotEqual(t, key, key2)
+
+	// 未设置商户单号或查询类请求不做幂等保护
+	_, ok = g.key(serviceRefund, V{"order_id": "202312010001"})
+	assert.False(t, ok)
+
+	_, ok = g.key(serviceQueryOrder, V{"order_id": "202312010001"})
+	assert.False(t, ok)
+
+	// 请求失败不缓存
+	_, err := g.do(context.Background(), key, fn)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	resp, err := g.do(context.Background(), key, fn)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+
+	// 返回结果互不影响
+	resp.Data.Set("ret_code", "9999")
+
+	resp, err = g.do(context.Background(), key, fn)
+	assert.Nil(t, err)
+	assert.Equal(t, OK, resp.Data.Get("ret_code"))
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyTransfer(t *testing.T) {
+	cli := newTestClient(t, WithIdempotency(time.Minute))
+
+	body, err := cli.ReplyHTML(V{"ret_code": OK, "order_id": "T202312010001", "trade_no": "3312010001", "trade_state": "16", "amount": "100"})
+	assert.Nil(t, err)
+
+	var calls int32
+
+	cli.httpCli = httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return mockResponse(http.StatusOK, body), nil
+	})
+
+	req := &TransferRequest{
+		OrderID:     "T202312010001",
+		MerDate:     "20231201",
+		Amount:      100,
+		AccountNO:   "6222020000000000000",
+		AccountName: "张三",
+		Purpose:     "提现",
+	}
+
+	// 收款账号每次加密的结果不同，仍视为重复的付款
+	for i := 0; i < 2; i++ {
+		resp, err := cli.Transfer(context.Background(), req)
+		assert.Nil(t, err)
+		assert.Equal(t, "3312010001", resp.TransferNO)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyResponseError(t *testing.T) {
+	cli := newTestClient(t, WithIdempotency(time.Minute))
+
+	body, err := cli.ReplyHTML(V{"ret_code": "00060710", "ret_msg": "order already paid"})
+	assert.Nil(t, err)
+
+	var calls int32
+
+	cli.httpCli = httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return mockResponse(http.StatusOK, body), nil
+	})
+
+	// 业务错误不缓存
+	for i := 0; i < 2; i++ {
+		err = cli.CloseOrder(context.Background(), "202312010001")
+		assert.ErrorIs(t, err, ErrOrderPaid)
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyCancel(t *testing.T) {
+	cli := newTestClient(t, WithIdempotency(time.Minute))
+
+	body, err := cli.ReplyHTML(V{"ret_code": OK, "order_id": "202312010001"})
+	assert.Nil(t, err)
+
+	var calls int32
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	cli.httpCli = httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		return mockResponse(http.StatusOK, body), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	first := make(chan error, 1)
+	go func() {
+		first <- cli.CloseOrder(ctx, "202312010001")
+	}()
+	<-started
+
+	second := make(chan error, 1)
+	go func() {
+		second <- cli.CloseOrder(context.Background(), "202312010001")
+	}()
+
+	// 首个调用方取消后，共享的请求继续完成，其它调用方不受影响
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	close(release)
+	assert.Nil(t, <-second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyHang(t *testing.T) {
+	timeout := sharedCallTimeout
+	sharedCallTimeout = 50 * time.Millisecond
+	defer func() { sharedCallTimeout = timeout }()
+
+	cli := newTestClient(t, WithIdempotency(time.Minute))
+
+	body, err := cli.ReplyHTML(V{"ret_code": OK, "order_id": "202312010001"})
+	assert.Nil(t, err)
+
+	var calls int32
+
+	// 首次请求网关无响应
+	cli.httpCli = httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		return mockResponse(http.StatusOK, body), nil
+	})
+
+	err = cli.CloseOrder(context.Background(), "202312010001")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// 超时的请求已移除，重试时重新发送
+	err = cli.CloseOrder(context.Background(), "202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 沿用调用方的截止时间
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	reqCtx, reqCancel := cli.sharedContext(ctx)
+	defer reqCancel()
+
+	want, _ := ctx.Deadline()
+	got, ok := reqCtx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, want, got)
+}
@@ -3,43 +3,199 @@ package soopay
 import (
 	"context"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// LogEntry 结构化的请求日志
+type LogEntry struct {
+	Method     string            // 请求方法
+	URL        string            // 请求地址
+	ReqHeader  http.Header       // 请求头
+	ReqBody    string            // 请求报文
+	StatusCode int               // HTTP状态码
+	RespHeader http.Header       // 返回头
+	RespBody   string            // 返回报文
+	Duration   time.Duration     // 请求耗时（从请求开始到读取完返回报文）
+	Attempts   int               // 请求次数（含重试）
+	Err        error             // 请求错误
+	Extra      map[string]string // 其它自定义K-V
+}
+
+// Logger 结构化日志接口，可对接 slog、zap 等日志库
+type Logger interface {
+	// LogRequest 请求发送前调用
+	LogRequest(ctx context.Context, entry *LogEntry)
+
+	// LogResponse 请求结束后调用（无论成功与否）
+	LogResponse(ctx context.Context, entry *LogEntry)
+}
+
+// SignStrLogger 可选接口；若 Logger 实现了该接口，则记录签名和验签时的待签名串，用于排查签名不一致的问题
+// 注意：待签名串包含全部业务参数，请注意日志中的敏感信息
+type SignStrLogger interface {
+	// LogSignStr action 为 sign（请求签名、ReplyHTML）或 verify（返回报文及通知验签）
+	LogSignStr(action, signStr string)
+}
+
+// WarnLogger 可选接口；若 Logger 实现了该接口，则通过其记录警告（如：选项未生效、熔断器状态变化），
+// 否则通过 LogResponse 记录，警告信息见 LogEntry.Extra 的 warn 字段
+type WarnLogger interface {
+	LogWarn(ctx context.Context, msg string)
+}
+
+// logWarn 记录警告；Logger 未实现 WarnLogger 时，以 LogResponse 记录
+func (c *Client) logWarn(ctx context.Context, msg string) {
+	if c.logger == nil {
+		return
+	}
+
+	if l, ok := c.logger.(WarnLogger); ok {
+		l.LogWarn(ctx, msg)
+		return
+	}
+
+	c.logger.LogResponse(ctx, &LogEntry{Extra: map[string]string{"level": "warn", "warn": msg}})
+}
+
+// MapLogger 将 K-V 形式的日志函数适配为 Logger，请求结束后记录一次日志
+type MapLogger func(ctx context.Context, data map[string]string)
+
+// LogRequest 实现 Logger 接口（不记录）
+func (f MapLogger) LogRequest(ctx context.Context, entry *LogEntry) {}
+
+// LogResponse 实现 Logger 接口
+func (f MapLogger) LogResponse(ctx context.Context, entry *LogEntry) {
+	f(ctx, entry.Map())
+}
+
+// Map 返回 K-V 形式的日志
+func (e *LogEntry) Map() map[string]string {
+	data := map[string]string{
+		"method": e.Method,
+		"url":    e.URL,
+	}
+
+	for k, v := range e.Extra {
+		data[k] = v
+	}
+
+	if e.ReqHeader != nil {
+		data["request_header"] = HeaderEncode(e.ReqHeader)
+	}
+
+	if len(e.ReqBody) != 0 {
+		data["request_body"] = e.ReqBody
+	}
+
+	if e.RespHeader != nil {
+		data["response_header"] = HeaderEncode(e.RespHeader)
+	}
+
+	if len(e.RespBody) != 0 {
+		data["response_body"] = e.RespBody
+	}
+
+	if e.StatusCode != 0 {
+		data["status_code"] = strconv.Itoa(e.StatusCode)
+	}
+
+	if e.Duration != 0 {
+		data["duration_ms"] = strconv.FormatInt(e.Duration.Milliseconds(), 10)
+	}
+
+	if e.Attempts != 0 {
+		data["attempts"] = strconv.Itoa(e.Attempts)
+	}
+
+	if e.Err != nil {
+		data["error"] = e.Err.Error()
+	}
+
+	return data
+}
+
 // ReqLog 请求日志
 type ReqLog struct {
-	data map[string]string
+	entry  *LogEntry
+	start  time.Time
+	skip   bool                // 未被采样，仅在请求失败时记录
+	redact func(string) string // 报文脱敏
 }
 
 // Set 设置日志K-V
 func (l *ReqLog) Set(k, v string) {
-	l.data[k] = v
+	l.entry.Extra[k] = v
 }
 
 // SetReqHeader 设置请求头
 func (l *ReqLog) SetReqHeader(h http.Header) {
-	l.data["request_header"] = HeaderEncode(h)
+	l.entry.ReqHeader = h
 }
 
 // SetBody 设置请求Body
 func (l *ReqLog) SetReqBody(v string) {
-	l.data["request_body"] = v
+	if l.redact != nil {
+		v = l.redact(v)
+	}
+
+	l.entry.ReqBody = v
 }
 
 // SetRespHeader 设置返回头
 func (l *ReqLog) SetRespHeader(h http.Header) {
-	l.data["response_header"] = HeaderEncode(h)
+	l.entry.RespHeader = h
 }
 
 // SetResp 设置返回报文
 func (l *ReqLog) SetRespBody(v string) {
-	l.data["response_body"] = v
+	if l.redact != nil {
+		v = l.redact(v)
+	}
+
+	l.entry.RespBody = v
 }
 
 // SetStatusCode 设置HTTP状态码
 func (l *ReqLog) SetStatusCode(code int) {
-	l.data["status_code"] = strconv.Itoa(code)
+	l.entry.StatusCode = code
+}
+
+// SetAttempts 设置请求次数
+func (l *ReqLog) SetAttempts(n int) {
+	l.entry.Attempts = n
+}
+
+// SetError 设置请求错误
+func (l *ReqLog) SetError(err error) {
+	l.entry.Err = err
+}
+
+// Entry 返回结构化日志
+func (l *ReqLog) Entry() *LogEntry {
+	return l.entry
+}
+
+// LogRequest 请求发送前记录日志
+func (l *ReqLog) LogRequest(ctx context.Context, logger Logger) {
+	if logger == nil || l.skip {
+		return
+	}
+
+	logger.LogRequest(ctx, l.entry)
+}
+
+// LogResponse 请求结束后记录日志
+func (l *ReqLog) LogResponse(ctx context.Context, logger Logger) {
+	if logger == nil || (l.skip && l.entry.Err == nil) {
+		return
+	}
+
+	l.entry.Duration = time.Since(l.start)
+
+	logger.LogResponse(ctx, l.entry)
 }
 
 // Do 日志记录
@@ -48,16 +204,42 @@ func (l *ReqLog) Do(ctx context.Context, log func(ctx context.Context, data map[
 		return
 	}
 
-	log(ctx, l.data)
+	l.entry.Duration = time.Since(l.start)
+
+	log(ctx, l.entry.Map())
 }
 
 // NewReqLog 生成请求日志
 func NewReqLog(method, reqURL string) *ReqLog {
 	return &ReqLog{
-		data: map[string]string{
-			"method": method,
-			"url":    reqURL,
+		entry: &LogEntry{
+			Method: method,
+			URL:    reqURL,
+			Extra:  make(map[string]string),
 		},
+		start: time.Now(),
+	}
+}
+
+// logMask 脱敏后的值
+const logMask = "***"
+
+// newRedactor 生成报文脱敏函数，将指定key的值替换为 ***；
+// 支持 K-V 表单（含HTML中的META内容）、JSON 及 XML 格式的报文
+func newRedactor(keys []string) func(string) string {
+	quoted := make([]string, 0, len(keys))
+	for _, k := range keys {
+		quoted = append(quoted, regexp.QuoteMeta(k))
+	}
+
+	alt := "(?:" + strings.Join(quoted, "|") + ")"
+
+	re := regexp.MustCompile(`((?:^|[&?"';\s])` + alt + `=)[^&"'\s<]*` + // K-V
+		`|("` + alt + `"\s*:\s*")[^"]*` + // JSON
+		`|(<` + alt + `>)[^<]*`) // XML
+
+	return func(s string) string {
+		return re.ReplaceAllString(s, "${1}${2}${3}"+logMask)
 	}
 }
 
@@ -0,0 +1,140 @@
+package soopay
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLogger struct {
+	requests  []*LogEntry
+	responses []*LogEntry
+}
+
+func (l *testLogger) LogRequest(ctx context.Context, entry *LogEntry) {
+	l.requests = append(l.requests, entry)
+}
+
+func (l *testLogger) LogResponse(ctx context.Context, entry *LogEntry) {
+	l.responses = append(l.responses, entry)
+}
+
+func TestLogger(t *testing.T) {
+	mock := &mockHTTPClient{
+		fn: func(n int, body []byte) (*http.Response, error) {
+			return mockResponse(http.StatusBadRequest, ""), nil
+		},
+	}
+
+	logger := new(testLogger)
+
+	cli := newTestClient(t, WithStructuredLogger(logger))
+	cli.httpCli = mock
+
+	_, err := cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, len(logger.requests))
+	assert.Equal(t, 1, len(logger.responses))
+
+	entry := logger.responses[0]
+	assert.Equal(t, http.MethodPost, entry.Method)
+	assert.Equal(t, cli.gateway, entry.URL)
+	assert.Contains(t, entry.ReqBody, "order_id=202312010001")
+	assert.Equal(t, defaultUserAgent, entry.ReqHeader.Get("User-Agent"))
+	assert.Equal(t, http.StatusBadRequest, entry.StatusCode)
+	assert.Equal(t, err, entry.Err)
+	assert.Equal(t, 1, entry.Attempts)
+	assert.NotZero(t, entry.Duration)
+
+	// K-V 日志
+	var data map[string]string
+
+	cli = newTestClient(t, WithUserAgent("merchant-app/2.0"), WithLogger(func(ctx context.Context, m map[string]string) {
+		data = m
+	}))
+	cli.httpCli = mock
+
+	_, err = cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "400", data["status_code"])
+	assert.Equal(t, err.Error(), data["error"])
+	assert.Equal(t, http.MethodPost, data["method"])
+	assert.Equal(t, "1", data["attempts"])
+	assert.Contains(t, data["request_header"], "User-Agent=merchant-app/2.0")
+	assert.Contains(t, data, "duration_ms")
+}
+
+type signStrLogger struct {
+	testLogger
+
+	signStrs map[string]string
+}
+
+func (l *signStrLogger) LogSignStr(action, signStr string) {
+	l.signStrs[action] = signStr
+}
+
+func TestSignStrLogger(t *testing.T) {
+	logger := &signStrLogger{signStrs: make(map[string]string)}
+
+	cli := newTestClient(t, WithStructuredLogger(logger))
+
+	form, err := cli.BuildForm("mer_order_info_query", V{"order_id": "202312010001"})
+	assert.Nil(t, err)
+	assert.Equal(t, "charset=UTF-8&mer_id=10001&order_id=202312010001&res_format=HTML&service=mer_order_info_query&version=4.0", logger.signStrs["sign"])
+
+	vals, err := url.ParseQuery(form)
+	assert.Nil(t, err)
+
+	_, err = cli.VerifyQuery(vals)
+	assert.Nil(t, err)
+	assert.Equal(t, logger.signStrs["sign"], logger.signStrs["verify"])
+}
+
+func TestRedactor(t *testing.T) {
+	redact := newRedactor([]string{"card_id", "media_id"})
+
+	assert.Equal(t, "amount=100&card_id=***&order_id=1&media_id=***", redact("amount=100&card_id=6222020000000000000&order_id=1&media_id=13800000000"))
+	assert.Equal(t, "card_id=***&my_card_id=123", redact("card_id=6222&my_card_id=123"))
+	assert.Equal(t, `<META NAME="MobilePayPlatform" CONTENT="card_id=***&amp;media_id=***&amp;ret_code=0000"/>`, redact(`<META NAME="MobilePayPlatform" CONTENT="card_id=6222&amp;media_id=138&amp;ret_code=0000"/>`))
+	assert.Equal(t, `{"card_id": "***","ret_code":"0000"}`, redact(`{"card_id": "6222","ret_code":"0000"}`))
+	assert.Equal(t, `<xml><card_id>***</card_id><ret_code>0000</ret_code></xml>`, redact(`<xml><card_id>6222</card_id><ret_code>0000</ret_code></xml>`))
+}
+
+func TestLogRedactAndSampling(t *testing.T) {
+	logger := new(testLogger)
+
+	cli := newTestClient(t, WithStructuredLogger(logger), WithLogRedactKeys("card_id"), WithLogSampling(0))
+
+	body, err := cli.ReplyHTML(V{"ret_code": OK, "card_id": "6222020000000000000"})
+	assert.Nil(t, err)
+
+	// 成功的请求不记录
+	cli.httpCli = &mockHTTPClient{
+		fn: func(n int, b []byte) (*http.Response, error) {
+			return mockResponse(http.StatusOK, body), nil
+		},
+	}
+
+	_, err = cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001", "card_id": "6222020000000000000"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(logger.requests))
+	assert.Equal(t, 0, len(logger.responses))
+
+	// 失败的请求总是记录
+	cli.httpCli = &mockHTTPClient{
+		fn: func(n int, body []byte) (*http.Response, error) {
+			return mockResponse(http.StatusBadRequest, ""), nil
+		},
+	}
+
+	_, err = cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001", "card_id": "6222020000000000000"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(logger.requests))
+	assert.Equal(t, 1, len(logger.responses))
+	assert.Contains(t, logger.responses[0].ReqBody, "card_id=***")
+	assert.NotContains(t, logger.responses[0].ReqBody, "6222020000000000000")
+}
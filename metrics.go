@@ -0,0 +1,13 @@
+package soopay
+
+import "time"
+
+// Metrics 请求指标（如：Prometheus），每次 Do 请求结束后调用；
+// 可通过 err 区分错误类型：
+//   - HTTP错误：statusCode 为 0（网络错误）或不为 200
+//   - 网关错误页面：errors.As(err, new(*GatewayError))
+//   - 验签失败：errors.As(err, new(*VerifyError))
+//   - 业务错误：errors.As(err, new(*ResponseError))，即返回码不为 OK
+type Metrics interface {
+	ObserveRequest(service string, statusCode int, duration time.Duration, err error)
+}
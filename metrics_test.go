@@ -0,0 +1,76 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMetrics struct {
+	service    string
+	statusCode int
+	err        error
+}
+
+func (m *testMetrics) ObserveRequest(service string, statusCode int, duration time.Duration, err error) {
+	m.service = service
+	m.statusCode = statusCode
+	m.err = err
+}
+
+func TestMetrics(t *testing.T) {
+	m := new(testMetrics)
+
+	cli := newTestClient(t, WithMetrics(m))
+
+	okBody, err := cli.ReplyHTML(V{"ret_code": OK})
+	assert.Nil(t, err)
+
+	bizBody, err := cli.ReplyHTML(V{"ret_code": codeOrderNotFound, "ret_msg": "订单不存在"})
+	assert.Nil(t, err)
+
+	mock := func(code int, body string) HTTPClient {
+		return &mockHTTPClient{
+			fn: func(n int, b []byte) (*http.Response, error) {
+				return mockResponse(code, body), nil
+			},
+		}
+	}
+
+	// 成功
+	cli.httpCli = mock(http.StatusOK, okBody)
+
+	_, err = cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.Nil(t, err)
+	assert.Equal(t, "mer_order_info_query", m.service)
+	assert.Equal(t, http.StatusOK, m.statusCode)
+	assert.Nil(t, m.err)
+
+	// 业务错误
+	cli.httpCli = mock(http.StatusOK, bizBody)
+
+	_, err = cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(m.err, ErrOrderNotFound))
+
+	// 验签失败
+	cli.httpCli = mock(http.StatusOK, `<html><head><META NAME="MobilePayPlatform" CONTENT="ret_code=0000&sign=xxx"/></head></html>`)
+
+	_, err = cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+
+	var verifyErr *VerifyError
+	assert.True(t, errors.As(m.err, &verifyErr))
+
+	// HTTP错误
+	cli.httpCli = mock(http.StatusBadGateway, "")
+
+	_, err = cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadGateway, m.statusCode)
+	assert.False(t, errors.As(m.err, &verifyErr))
+}
@@ -0,0 +1,96 @@
+package soopay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HTTPMiddleware HTTP客户端中间件，用于拦截请求及响应（如：录制、故障注入、链路追踪）
+type HTTPMiddleware func(next HTTPClient) HTTPClient
+
+// HTTPClientFunc 函数形式的 HTTPClient，便于实现中间件
+type HTTPClientFunc func(ctx context.Context, method, reqURL string, body []byte, options ...HTTPOption) (*http.Response, error)
+
+// Do 实现 HTTPClient 接口
+func (f HTTPClientFunc) Do(ctx context.Context, method, reqURL string, body []byte, options ...HTTPOption) (*http.Response, error) {
+	return f(ctx, method, reqURL, body, options...)
+}
+
+// Exchange 一次请求及响应的记录
+type Exchange struct {
+	Service    string      `json:"service"`
+	OrderID    string      `json:"order_id,omitempty"`
+	Request    string      `json:"request"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Response   string      `json:"response"`
+}
+
+// exchangeFile 记录文件名：{service}_{order_id}.json，非法字符替换为下划线
+func exchangeFile(service, orderID string) string {
+	name := service
+	if len(orderID) != 0 {
+		name += "_" + orderID
+	}
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+
+		return '_'
+	}, name)
+
+	return name + ".json"
+}
+
+// NewRecordMiddleware 将请求及响应记录到目录 dir（JSON格式，文件名：{service}_{order_id}.json），用于生成测试的 golden 文件；
+// 同一服务及订单号的记录会被覆盖；写入失败时返回错误（网关可能已处理该请求），仅用于测试环境
+func NewRecordMiddleware(dir string) HTTPMiddleware {
+	return func(next HTTPClient) HTTPClient {
+		return HTTPClientFunc(func(ctx context.Context, method, reqURL string, body []byte, options ...HTTPOption) (*http.Response, error) {
+			resp, err := next.Do(ctx, method, reqURL, body, options...)
+			if err != nil {
+				return resp, err
+			}
+
+			b, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if err != nil {
+				return nil, err
+			}
+
+			resp.Body = io.NopCloser(bytes.NewReader(b))
+
+			form, _ := ParseV(string(body))
+
+			ex := &Exchange{
+				Service:    form.Get("service"),
+				OrderID:    form.Get("order_id"),
+				Request:    string(body),
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header,
+				Response:   string(b),
+			}
+
+			data, err := json.MarshalIndent(ex, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+
+			if err = os.WriteFile(filepath.Join(dir, exchangeFile(ex.Service, ex.OrderID)), data, 0o644); err != nil {
+				return nil, err
+			}
+
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package soopay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithHTTPMiddleware(t *testing.T) {
+	gateway := newTestClient(t)
+
+	body, err := gateway.ReplyHTML(V{"ret_code": OK, "order_id": "202312010001", "trade_state": "TRADE_SUCCESS", "amount": "100"})
+	assert.Nil(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	var calls []string
+
+	trace := func(name string) HTTPMiddleware {
+		return func(next HTTPClient) HTTPClient {
+			return HTTPClientFunc(func(ctx context.Context, method, reqURL string, body []byte, options ...HTTPOption) (*http.Response, error) {
+				calls = append(calls, name)
+				return next.Do(ctx, method, reqURL, body, options...)
+			})
+		}
+	}
+
+	dir := t.TempDir()
+
+	cli := newTestClient(t, WithGateway(srv.URL), WithHTTPMiddleware(trace("a"), trace("b")), WithHTTPMiddleware(NewRecordMiddleware(dir)))
+
+	status, err := cli.QueryOrder(context.Background(), "202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, TradeSuccess, status.Status)
+
+	// 先设置的中间件在外层
+	assert.Equal(t, []string{"a", "b"}, calls)
+
+	// 录制的请求及响应
+	b, err := os.ReadFile(filepath.Join(dir, "mer_order_info_query_202312010001.json"))
+	assert.Nil(t, err)
+
+	ex := new(Exchange)
+	assert.Nil(t, json.Unmarshal(b, ex))
+	assert.Equal(t, serviceQueryOrder, ex.Service)
+	assert.Equal(t, "202312010001", ex.OrderID)
+	assert.Equal(t, http.StatusOK, ex.StatusCode)
+	assert.Equal(t, body, ex.Response)
+	assert.Contains(t, ex.Request, "order_id=202312010001")
+}
+
+func TestExchangeFile(t *testing.T) {
+	assert.Equal(t, "mer_refund_R_01.json", exchangeFile("mer_refund", "R/01"))
+	assert.Equal(t, "query_mer_balance.json", exchangeFile("query_mer_balance", ""))
+}
@@ -0,0 +1,127 @@
+package soopay
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// maxNotifyBodySize 异步通知报文的最大长度，防止伪造请求耗尽内存
+const maxNotifyBodySize = 1 << 20
+
+// VerifyNotify 验证异步通知；
+// 支持 GET 查询参数、application/x-www-form-urlencoded 表单以及HTML（MobilePayPlatform meta）三种形式；
+// 若设置了 WithNotifyTimestampTolerance，则同时校验通知的 time_stamp
+func (c *Client) VerifyNotify(r *http.Request) (V, error) {
+	data, err := c.verifyNotify(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.checkNotifyTime(data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (c *Client) verifyNotify(r *http.Request) (V, error) {
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(nil, r.Body, maxNotifyBodySize)
+	}
+
+	// 回调地址自带的查询参数（如：?channel=soopay）不参与验签，POST 时仅使用表单中的字段
+	if r.Method == http.MethodGet {
+		return c.verifyValues(r.URL.Query())
+	}
+
+	if isFormRequest(r) {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+
+		return c.verifyValues(r.PostForm)
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.VerifyHTML(b)
+}
+
+func isFormRequest(r *http.Request) bool {
+	ct := r.Header.Get("Content-Type")
+	if len(ct) == 0 {
+		return false
+	}
+
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+
+	return mt == "application/x-www-form-urlencoded"
+}
+
+// Notification 支付结果异步通知
+type Notification struct {
+	OrderID string      // 商户订单号
+	TradeNO string      // 平台交易号
+	Status  TradeStatus // 交易状态
+	Amount  Amount      // 支付金额
+	PayTime time.Time   // 支付时间
+	MerPriv string      // 商户私有域
+	Data    V           // 通知的完整数据
+}
+
+// ParseNotify 验证并解析支付结果异步通知
+func (c *Client) ParseNotify(r *http.Request) (*Notification, error) {
+	data, err := c.VerifyNotify(r)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseAmount(data.Get("amount"))
+	if err != nil {
+		return nil, err
+	}
+
+	payTime, err := ParseGatewayTime(data.Get("pay_time"))
+	if err != nil {
+		return nil, err
+	}
+
+	notify := &Notification{
+		OrderID: data.Get("order_id"),
+		TradeNO: data.Get("trade_no"),
+		Status:  ParseTradeStatus(data.Get("trade_state")),
+		Amount:  amount,
+		PayTime: payTime,
+		MerPriv: data.Get("mer_priv"),
+		Data:    data,
+	}
+
+	return notify, nil
+}
+
+// 通知应答失败时的返回码
+const replyFailCode = "1111"
+
+// SuccessReply 生成处理成功的通知应答（已签名的HTML报文）
+func (c *Client) SuccessReply() (string, error) {
+	return c.ReplyHTML(V{
+		"ret_code": OK,
+		"ret_msg":  "success",
+	})
+}
+
+// FailReply 生成处理失败的通知应答（已签名的HTML报文），网关将重新发送通知
+func (c *Client) FailReply(reason string) (string, error) {
+	return c.ReplyHTML(V{
+		"ret_code": replyFailCode,
+		"ret_msg":  reason,
+	})
+}
@@ -0,0 +1,95 @@
+package soopay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyNotify(t *testing.T) {
+	cli := newTestClient(t)
+
+	data := V{"order_id": "202312010001", "amount": "100", "trade_state": "TRADE_SUCCESS"}
+
+	query, err := cli.BuildForm("pay_result_notify", data)
+	assert.Nil(t, err)
+
+	// GET
+	r := httptest.NewRequest(http.MethodGet, "/notify?"+query, nil)
+
+	ret, err := cli.VerifyNotify(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "TRADE_SUCCESS", ret.Get("trade_state"))
+
+	// POST 表单
+	r = httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(query))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+
+	ret, err = cli.VerifyNotify(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "202312010001", ret.Get("order_id"))
+
+	// POST 表单，回调地址带有查询参数（含与表单重复的字段）
+	r = httptest.NewRequest(http.MethodPost, "/notify?channel=soopay&order_id=202312010001", strings.NewReader(query))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	ret, err = cli.VerifyNotify(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "202312010001", ret.Get("order_id"))
+	assert.False(t, ret.Has("channel"))
+
+	// 报文过大
+	r = httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(strings.Repeat("a", maxNotifyBodySize+1)))
+
+	_, err = cli.VerifyNotify(r)
+	assert.NotNil(t, err)
+}
+
+func TestParseNotify(t *testing.T) {
+	cli := newTestClient(t)
+
+	data := V{
+		"order_id":    "202312010001",
+		"trade_no":    "3312010001",
+		"trade_state": "TRADE_SUCCESS",
+		"amount":      "100",
+		"pay_time":    "20231201153045",
+	}
+
+	form, err := cli.BuildForm("pay_result_notify", data)
+	assert.Nil(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/notify?"+form, nil)
+
+	notify, err := cli.ParseNotify(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "202312010001", notify.OrderID)
+	assert.Equal(t, "3312010001", notify.TradeNO)
+	assert.Equal(t, TradeSuccess, notify.Status)
+	assert.Equal(t, AmountFromCents(100), notify.Amount)
+	assert.Equal(t, time.Date(2023, 12, 1, 7, 30, 45, 0, time.UTC), notify.PayTime.UTC())
+}
+
+func TestNotifyReply(t *testing.T) {
+	cli := newTestClient(t)
+
+	body, err := cli.SuccessReply()
+	assert.Nil(t, err)
+
+	ret, err := cli.VerifyHTML([]byte(body))
+	assert.Nil(t, err)
+	assert.Equal(t, OK, ret.Get("ret_code"))
+	assert.Equal(t, "10001", ret.Get("mer_id"))
+
+	body, err = cli.FailReply("系统繁忙")
+	assert.Nil(t, err)
+
+	ret, err = cli.VerifyHTML([]byte(body))
+	assert.Nil(t, err)
+	assert.Equal(t, replyFailCode, ret.Get("ret_code"))
+	assert.Equal(t, "系统繁忙", ret.Get("ret_msg"))
+}
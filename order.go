@@ -0,0 +1,224 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const serviceUnifiedOrder = "active_scancode_order"
+
+// UnifiedOrderRequest 下单请求
+type UnifiedOrderRequest struct {
+	OrderID      string // 商户订单号（必填）
+	Amount       Amount // 订单金额（必填）
+	Subject      string // 商品描述（必填）
+	NotifyURL    string // 异步通知地址（必填）
+	ScancodeType string // 扫码类型，如：WECHAT、ALIPAY（必填）
+	UserIP       string // 用户IP
+	ExpireTime   string // 订单有效时长，单位：分钟
+	MerPriv      string // 商户私有域，原样返回
+}
+
+func (r *UnifiedOrderRequest) validate() error {
+	if len(r.OrderID) == 0 {
+		return errors.New("order_id is required")
+	}
+
+	if r.Amount <= 0 {
+		return errors.New("amount must be greater than 0")
+	}
+
+	if len(r.Subject) == 0 {
+		return errors.New("subject is required")
+	}
+
+	if len(r.NotifyURL) == 0 {
+		return errors.New("notify_url is required")
+	}
+
+	if len(r.ScancodeType) == 0 {
+		return errors.New("scancode_type is required")
+	}
+
+	return nil
+}
+
+func (r *UnifiedOrderRequest) bizData() V {
+	v := V{}
+
+	v.Set("order_id", r.OrderID)
+	v.Set("amount", r.Amount.String())
+	v.Set("amt_type", "RMB")
+	v.Set("goods_inf", r.Subject)
+	v.Set("notify_url", r.NotifyURL)
+	v.Set("scancode_type", r.ScancodeType)
+	v.Set("user_ip", r.UserIP)
+	v.Set("expire_time", r.ExpireTime)
+	v.Set("mer_priv", r.MerPriv)
+
+	return v
+}
+
+// UnifiedOrderResponse 下单结果
+type UnifiedOrderResponse struct {
+	OrderID string // 商户订单号
+	TradeNO string // 平台交易号
+	PayURL  string // 支付链接（二维码内容）
+	MerPriv string // 商户私有域
+}
+
+// UnifiedOrder 下单
+func (c *Client) UnifiedOrder(ctx context.Context, req *UnifiedOrderRequest) (*UnifiedOrderResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	ret, err := c.Do(ctx, serviceUnifiedOrder, req.bizData())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkRetCode(ret); err != nil {
+		return nil, err
+	}
+
+	resp := &UnifiedOrderResponse{
+		OrderID: ret.Get("order_id"),
+		TradeNO: ret.Get("trade_no"),
+		PayURL:  ret.Get("bank_payurl"),
+		MerPriv: ret.Get("mer_priv"),
+	}
+
+	return resp, nil
+}
+
+const serviceQueryOrder = "mer_order_info_query"
+
+// 订单相关的网关返回码
+const (
+	codeOrderNotFound = "00060700" // 订单不存在
+	codeOrderPaid     = "00060710" // 订单已支付
+	codeOrderClosed   = "00060711" // 订单已关闭
+)
+
+var (
+	// ErrOrderNotFound 订单不存在
+	ErrOrderNotFound = &ResponseError{Code: codeOrderNotFound, Msg: "order not found"}
+	// ErrOrderPaid 订单已支付
+	ErrOrderPaid = &ResponseError{Code: codeOrderPaid, Msg: "order already paid"}
+	// ErrOrderClosed 订单已关闭
+	ErrOrderClosed = &ResponseError{Code: codeOrderClosed, Msg: "order already closed"}
+)
+
+// TradeStatus 交易状态
+type TradeStatus int
+
+const (
+	TradeUnknown TradeStatus = iota // 未知状态
+	TradeWaitPay                    // 待支付：WAIT_BUYER_PAY
+	TradeSuccess                    // 支付成功：TRADE_SUCCESS
+	TradeClosed                     // 已关闭：TRADE_CLOSED
+	TradeCancel                     // 已撤销：TRADE_CANCEL
+	TradeFail                       // 支付失败：TRADE_FAIL
+)
+
+var tradeStatusText = map[TradeStatus]string{
+	TradeUnknown: "UNKNOWN",
+	TradeWaitPay: "WAIT_BUYER_PAY",
+	TradeSuccess: "TRADE_SUCCESS",
+	TradeClosed:  "TRADE_CLOSED",
+	TradeCancel:  "TRADE_CANCEL",
+	TradeFail:    "TRADE_FAIL",
+}
+
+// String 返回网关的状态码
+func (s TradeStatus) String() string {
+	if v, ok := tradeStatusText[s]; ok {
+		return v
+	}
+
+	return tradeStatusText[TradeUnknown]
+}
+
+// ParseTradeStatus 解析网关返回的交易状态
+func ParseTradeStatus(s string) TradeStatus {
+	for k, v := range tradeStatusText {
+		if v == s {
+			return k
+		}
+	}
+
+	return TradeUnknown
+}
+
+// OrderStatus 订单状态
+type OrderStatus struct {
+	OrderID string      // 商户订单号
+	TradeNO string      // 平台交易号
+	Status  TradeStatus // 交易状态
+	Amount  Amount      // 支付金额
+	PayTime time.Time   // 支付时间（未支付时为零值）
+	Outcome Outcome     // 交易结果，为 OutcomePending 时应继续查询
+	RetCode string      // 网关返回码
+}
+
+// QueryOrder 订单查询；订单不存在时返回的错误满足 errors.Is(err, ErrOrderNotFound)
+// 注意：设置 WithQueryCoalescing 后，并发的相同查询共享一次网关请求
+func (c *Client) QueryOrder(ctx context.Context, orderID string) (*OrderStatus, error) {
+	if len(orderID) == 0 {
+		return nil, errors.New("order_id is required")
+	}
+
+	ret, err := c.doQuery(ctx, serviceQueryOrder, orderID, V{"order_id": orderID})
+	if err != nil {
+		return nil, err
+	}
+
+	done, err := c.checkOutcome(ret)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseAmount(ret.Get("amount"))
+	if err != nil {
+		return nil, err
+	}
+
+	payTime, err := ParseGatewayTime(ret.Get("pay_time"))
+	if err != nil {
+		return nil, err
+	}
+
+	status := &OrderStatus{
+		OrderID: ret.Get("order_id"),
+		TradeNO: ret.Get("trade_no"),
+		Status:  ParseTradeStatus(ret.Get("trade_state")),
+		Amount:  amount,
+		PayTime: payTime,
+		RetCode: ret.Get("ret_code"),
+	}
+
+	if done {
+		status.Outcome = status.Status.Outcome()
+	}
+
+	return status, nil
+}
+
+const serviceCloseOrder = "mer_cancel"
+
+// CloseOrder 关闭未支付的订单；
+// 关闭失败时返回 ResponseError，可通过 errors.Is 与 ErrOrderPaid、ErrOrderClosed、ErrOrderNotFound 比较
+func (c *Client) CloseOrder(ctx context.Context, orderID string) error {
+	if len(orderID) == 0 {
+		return errors.New("order_id is required")
+	}
+
+	ret, err := c.Do(ctx, serviceCloseOrder, V{"order_id": orderID})
+	if err != nil {
+		return err
+	}
+
+	return checkRetCode(ret)
+}
@@ -0,0 +1,93 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedOrderRequest(t *testing.T) {
+	req := &UnifiedOrderRequest{
+		OrderID:      "202312010001",
+		Amount:       100,
+		Subject:      "test",
+		NotifyURL:    "https://example.com/notify",
+		ScancodeType: "WECHAT",
+	}
+	assert.Nil(t, req.validate())
+	assert.Equal(t, "amount=100&amt_type=RMB&goods_inf=test&notify_url=https://example.com/notify&order_id=202312010001&scancode_type=WECHAT", req.bizData().Encode("=", "&", WithEmptyMode(EmptyIgnore)))
+
+	req.Amount = 0
+	assert.EqualError(t, req.validate(), "amount must be greater than 0")
+
+	req.Amount = 100
+	req.NotifyURL = ""
+	assert.EqualError(t, req.validate(), "notify_url is required")
+}
+
+func TestTradeStatus(t *testing.T) {
+	assert.Equal(t, TradeSuccess, ParseTradeStatus("TRADE_SUCCESS"))
+	assert.Equal(t, TradeUnknown, ParseTradeStatus("UNKNOWN_STATE"))
+	assert.Equal(t, "WAIT_BUYER_PAY", TradeWaitPay.String())
+	assert.Equal(t, "UNKNOWN", TradeStatus(100).String())
+}
+
+func TestQueryOrderPayTime(t *testing.T) {
+	gateway := newTestClient(t)
+
+	reply := V{"ret_code": OK, "order_id": "202312010001", "trade_state": "TRADE_SUCCESS", "amount": "100", "pay_time": "20231201153045"}
+
+	cli := newTestClient(t, WithHTTPClient(&mockHTTPClient{fn: func(n int, body []byte) (*http.Response, error) {
+		html, _ := gateway.ReplyHTML(reply)
+		return mockResponse(http.StatusOK, html), nil
+	}}))
+
+	status, err := cli.QueryOrder(context.Background(), "202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2023, 12, 1, 7, 30, 45, 0, time.UTC), status.PayTime.UTC())
+
+	reply.Set("pay_time", "invalid")
+	_, err = cli.QueryOrder(context.Background(), "202312010001")
+	assert.NotNil(t, err)
+}
+
+func TestCloseOrder(t *testing.T) {
+	gateway := newTestClient(t)
+
+	var (
+		form  V
+		reply V
+	)
+
+	cli := newTestClient(t, WithHTTPClient(&mockHTTPClient{fn: func(n int, body []byte) (*http.Response, error) {
+		form, _ = ParseV(string(body))
+
+		html, _ := gateway.ReplyHTML(reply)
+		return mockResponse(http.StatusOK, html), nil
+	}}))
+
+	reply = V{"ret_code": OK, "order_id": "202312010001"}
+
+	err := cli.CloseOrder(context.Background(), "202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, serviceCloseOrder, form.Get("service"))
+	assert.Equal(t, "202312010001", form.Get("order_id"))
+
+	// 业务错误
+	reply = V{"ret_code": codeOrderPaid, "ret_msg": "order already paid"}
+
+	err = cli.CloseOrder(context.Background(), "202312010001")
+	assert.True(t, errors.Is(err, ErrOrderPaid))
+	assert.False(t, errors.Is(err, ErrOrderClosed))
+
+	reply = V{"ret_code": codeOrderClosed, "ret_msg": "order already closed"}
+
+	err = cli.CloseOrder(context.Background(), "202312010001")
+	assert.True(t, errors.Is(err, ErrOrderClosed))
+
+	assert.EqualError(t, cli.CloseOrder(context.Background(), ""), "order_id is required")
+}
@@ -0,0 +1,68 @@
+// Package soopayotel 基于 OpenTelemetry 的链路追踪，独立为子模块，避免未使用的项目引入 OpenTelemetry 依赖
+// 注意：soopay-go 尚未发布包含所需接口的正式版本，go.mod 通过 replace 使用本地代码，发布后改为依赖该版本
+package soopayotel
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shenghui0779/soopay-go"
+)
+
+// instrumentationName 使用全局 TracerProvider 时的 Tracer 名称
+const instrumentationName = "github.com/shenghui0779/soopay-go/otel"
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer 通过 OpenTelemetry 的 Tracer 生成 soopay.Tracer；tracer 为 nil 时使用全局 TracerProvider
+func NewTracer(t trace.Tracer) soopay.Tracer {
+	if t == nil {
+		t = otel.Tracer(instrumentationName)
+	}
+
+	return &tracer{tracer: t}
+}
+
+// WithTracer 设置 OpenTelemetry 链路追踪，等同于 soopay.WithTracer(NewTracer(t))
+func WithTracer(t trace.Tracer) soopay.Option {
+	return soopay.WithTracer(NewTracer(t))
+}
+
+func (t *tracer) Start(ctx context.Context, service string) (context.Context, soopay.Span) {
+	ctx, span := t.tracer.Start(ctx, "soopay "+service,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("soopay.service", service)),
+	)
+
+	return ctx, &otelSpan{span: span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+// End 记录HTTP状态码及错误；业务错误同时记录返回码（soopay.ret_code）
+func (s *otelSpan) End(statusCode int, err error) {
+	if statusCode != 0 {
+		s.span.SetAttributes(attribute.Int("http.status_code", statusCode))
+	}
+
+	if err != nil {
+		var respErr *soopay.ResponseError
+		if errors.As(err, &respErr) {
+			s.span.SetAttributes(attribute.String("soopay.ret_code", respErr.Code))
+		}
+
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+
+	s.span.End()
+}
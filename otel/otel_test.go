@@ -0,0 +1,49 @@
+package soopayotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/shenghui0779/soopay-go"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tracer := NewTracer(provider.Tracer("test"))
+
+	ctx, span := tracer.Start(context.Background(), "mer_order_info_query")
+	assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+
+	span.End(200, &soopay.ResponseError{Code: "00060700", Msg: "订单不存在"})
+
+	_, span = tracer.Start(context.Background(), "mer_refund")
+	span.End(0, errors.New("connection refused"))
+
+	_, span = tracer.Start(context.Background(), "mer_refund")
+	span.End(200, nil)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+
+	assert.Equal(t, "soopay mer_order_info_query", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("soopay.service", "mer_order_info_query"))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.status_code", 200))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("soopay.ret_code", "00060700"))
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.NotContains(t, spans[1].Attributes(), attribute.Int("http.status_code", 0))
+
+	assert.Equal(t, codes.Unset, spans[2].Status().Code)
+}
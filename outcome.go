@@ -0,0 +1,93 @@
+package soopay
+
+// Outcome 交易结果：成功、处理中、失败
+type Outcome int
+
+const (
+	OutcomePending Outcome = iota // 处理中（含未知状态），应继续查询
+	OutcomeSuccess                // 成功
+	OutcomeFailed                 // 失败
+)
+
+var outcomeText = map[Outcome]string{
+	OutcomePending: "PENDING",
+	OutcomeSuccess: "SUCCESS",
+	OutcomeFailed:  "FAILED",
+}
+
+// String 返回结果描述
+func (o Outcome) String() string {
+	if v, ok := outcomeText[o]; ok {
+		return v
+	}
+
+	return outcomeText[OutcomePending]
+}
+
+// codeProcessing 网关返回码：交易处理中（非失败，应继续查询）
+const codeProcessing = "00200014"
+
+// WithPendingCodes 设置额外的「处理中」返回码（默认：00200014）；
+// 查询及交易接口收到这些返回码时不返回错误，而是返回 Outcome 为 OutcomePending 的结果
+func WithPendingCodes(codes ...string) Option {
+	return func(c *Client) {
+		for _, v := range codes {
+			c.pendingCodes[v] = struct{}{}
+		}
+	}
+}
+
+// isPending 判断返回码是否为「处理中」
+func (c *Client) isPending(code string) bool {
+	_, ok := c.pendingCodes[code]
+	return ok
+}
+
+// checkOutcome 校验网关返回码；返回码为 OK 时返回 true，为「处理中」时返回 false，其余返回 ResponseError
+func (c *Client) checkOutcome(data V) (bool, error) {
+	if c.isPending(data.Get("ret_code")) {
+		return false, nil
+	}
+
+	if err := checkRetCode(data); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Outcome 交易结果
+func (s TradeStatus) Outcome() Outcome {
+	switch s {
+	case TradeSuccess:
+		return OutcomeSuccess
+	case TradeClosed, TradeCancel, TradeFail:
+		return OutcomeFailed
+	}
+
+	return OutcomePending
+}
+
+// Outcome 退款结果
+func (s RefundState) Outcome() Outcome {
+	switch s {
+	case RefundSuccess:
+		return OutcomeSuccess
+	case RefundFail:
+		return OutcomeFailed
+	}
+
+	return OutcomePending
+}
+
+// Outcome 付款结果
+func (s TransferState) Outcome() Outcome {
+	switch s {
+	case TransferSuccess:
+		return OutcomeSuccess
+	case TransferFail:
+		return OutcomeFailed
+	}
+
+	return OutcomePending
+}
@@ -0,0 +1,74 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, OutcomeSuccess, TradeSuccess.Outcome())
+	assert.Equal(t, OutcomePending, TradeWaitPay.Outcome())
+	assert.Equal(t, OutcomePending, TradeUnknown.Outcome())
+	assert.Equal(t, OutcomeFailed, TradeClosed.Outcome())
+
+	assert.Equal(t, OutcomeSuccess, RefundSuccess.Outcome())
+	assert.Equal(t, OutcomePending, RefundProcessing.Outcome())
+	assert.Equal(t, OutcomeFailed, RefundFail.Outcome())
+
+	assert.Equal(t, OutcomeSuccess, TransferSuccess.Outcome())
+	assert.Equal(t, OutcomePending, TransferProcessing.Outcome())
+	assert.Equal(t, OutcomeFailed, TransferFail.Outcome())
+
+	assert.Equal(t, "PENDING", OutcomePending.String())
+	assert.Equal(t, "PENDING", Outcome(9).String())
+}
+
+func TestPendingCodes(t *testing.T) {
+	gateway := newTestClient(t)
+
+	var reply V
+
+	cli := newTestClient(t, WithPendingCodes("00200099"), WithHTTPClient(&mockHTTPClient{fn: func(n int, body []byte) (*http.Response, error) {
+		html, _ := gateway.ReplyHTML(reply)
+		return mockResponse(http.StatusOK, html), nil
+	}}))
+
+	reply = V{"ret_code": OK, "order_id": "202312010001", "trade_state": "TRADE_SUCCESS", "amount": "100"}
+
+	status, err := cli.QueryOrder(context.Background(), "202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, OutcomeSuccess, status.Outcome)
+	assert.Equal(t, OK, status.RetCode)
+
+	// 处理中的返回码不视为错误
+	for _, code := range []string{codeProcessing, "00200099"} {
+		reply = V{"ret_code": code, "ret_msg": "processing", "order_id": "202312010001"}
+
+		status, err = cli.QueryOrder(context.Background(), "202312010001")
+		assert.Nil(t, err)
+		assert.Equal(t, OutcomePending, status.Outcome)
+		assert.Equal(t, code, status.RetCode)
+	}
+
+	reply = V{"ret_code": codeProcessing, "refund_no": "R202312010001"}
+
+	refund, err := cli.RefundQuery(context.Background(), "R202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, OutcomePending, refund.Outcome)
+
+	reply = V{"ret_code": OK, "refund_no": "R202312010001", "refund_state": "REFUND_FAIL"}
+
+	refund, err = cli.RefundQuery(context.Background(), "R202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, OutcomeFailed, refund.Outcome)
+
+	// 其它返回码仍返回错误
+	reply = V{"ret_code": "00060700", "ret_msg": "order not found"}
+
+	_, err = cli.QueryOrder(context.Background(), "202312010001")
+	assert.True(t, errors.Is(err, ErrOrderNotFound))
+}
@@ -0,0 +1,90 @@
+package soopay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// RecordingHTTPClient 录制请求及响应到目录（格式同 NewRecordMiddleware），用于生成 ReplayHTTPClient 回放的 golden 文件；
+// 通过 WithHTTPClient 设置
+type RecordingHTTPClient struct {
+	client HTTPClient
+}
+
+// NewRecordingHTTPClient 生成录制的HTTP客户端，实际请求由 next 发送（nil 时使用默认的HTTP客户端）
+func NewRecordingHTTPClient(dir string, next HTTPClient) *RecordingHTTPClient {
+	if next == nil {
+		next = NewDefaultHTTPClient()
+	}
+
+	return &RecordingHTTPClient{client: NewRecordMiddleware(dir)(next)}
+}
+
+// Do 实现 HTTPClient 接口
+func (c *RecordingHTTPClient) Do(ctx context.Context, method, reqURL string, body []byte, options ...HTTPOption) (*http.Response, error) {
+	return c.client.Do(ctx, method, reqURL, body, options...)
+}
+
+// ErrNoRecordedExchange 回放时未找到对应的录制记录
+var ErrNoRecordedExchange = errors.New("replay: no recorded exchange")
+
+// ReplayHTTPClient 回放 RecordingHTTPClient 录制的响应，按请求的 service 及 order_id 匹配，不发送网络请求；
+// 通过 WithHTTPClient 设置，用于可重复的集成测试
+type ReplayHTTPClient struct {
+	dir string
+}
+
+// NewReplayHTTPClient 生成回放的HTTP客户端，dir 为录制的目录
+func NewReplayHTTPClient(dir string) *ReplayHTTPClient {
+	return &ReplayHTTPClient{dir: dir}
+}
+
+// Do 实现 HTTPClient 接口；未找到录制记录时返回的错误满足 errors.Is(err, ErrNoRecordedExchange)
+func (c *ReplayHTTPClient) Do(ctx context.Context, method, reqURL string, body []byte, options ...HTTPOption) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	form, err := ParseV(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	service, orderID := form.Get("service"), form.Get("order_id")
+
+	b, err := os.ReadFile(filepath.Join(c.dir, exchangeFile(service, orderID)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: service = %s, order_id = %s", ErrNoRecordedExchange, service, orderID)
+		}
+
+		return nil, err
+	}
+
+	ex := new(Exchange)
+	if err = json.Unmarshal(b, ex); err != nil {
+		return nil, err
+	}
+
+	header := ex.Header
+	if header == nil {
+		header = http.Header{}
+	}
+
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.StatusCode, http.StatusText(ex.StatusCode)),
+		StatusCode:    ex.StatusCode,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(ex.Response))),
+		ContentLength: int64(len(ex.Response)),
+	}
+
+	return resp, nil
+}
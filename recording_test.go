@@ -0,0 +1,51 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	gateway := newTestClient(t)
+
+	var calls int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		r.ParseForm()
+
+		body, _ := gateway.ReplyHTML(V{"ret_code": OK, "order_id": r.Form.Get("order_id"), "trade_state": "TRADE_SUCCESS", "amount": "100"})
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	// 录制
+	cli := newTestClient(t, WithGateway(srv.URL), WithHTTPClient(NewRecordingHTTPClient(dir, nil)))
+
+	for _, orderID := range []string{"202312010001", "202312010002"} {
+		_, err := cli.QueryOrder(context.Background(), orderID)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 2, calls)
+
+	// 回放，不发送网络请求
+	cli = newTestClient(t, WithHTTPClient(NewReplayHTTPClient(dir)))
+
+	status, err := cli.QueryOrder(context.Background(), "202312010002")
+	assert.Nil(t, err)
+	assert.Equal(t, "202312010002", status.OrderID)
+	assert.Equal(t, Amount(100), status.Amount)
+	assert.Equal(t, 2, calls)
+
+	_, err = cli.QueryOrder(context.Background(), "202312010003")
+	assert.True(t, errors.Is(err, ErrNoRecordedExchange))
+	assert.EqualError(t, err, "replay: no recorded exchange: service = mer_order_info_query, order_id = 202312010003")
+}
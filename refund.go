@@ -0,0 +1,228 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const serviceRefund = "mer_refund"
+
+// RefundRequest 退款请求
+type RefundRequest struct {
+	OrderID   string // 原商户订单号（必填）
+	RefundID  string // 商户退款单号（必填）
+	Amount    Amount // 退款金额（必填）
+	OrgAmount Amount // 原订单支付金额；若设置，则校验退款金额不超过该金额
+	TradeNO   string // 原订单的平台交易号
+	Reason    string // 退款原因
+}
+
+func (r *RefundRequest) validate() error {
+	if len(r.OrderID) == 0 {
+		return errors.New("order_id is required")
+	}
+
+	if len(r.RefundID) == 0 {
+		return errors.New("refund_no is required")
+	}
+
+	if r.Amount <= 0 {
+		return errors.New("refund_amount must be greater than 0")
+	}
+
+	if r.OrgAmount > 0 && r.Amount > r.OrgAmount {
+		return errors.New("refund_amount exceeds the original paid amount")
+	}
+
+	return nil
+}
+
+func (r *RefundRequest) bizData() V {
+	v := V{}
+
+	v.Set("order_id", r.OrderID)
+	v.Set("refund_no", r.RefundID)
+	v.Set("refund_amount", r.Amount.String())
+	v.Set("refund_desc", r.Reason)
+
+	if r.OrgAmount > 0 {
+		v.Set("org_amount", r.OrgAmount.String())
+	}
+
+	if len(r.TradeNO) != 0 {
+		v.Set("trade_no", r.TradeNO)
+	}
+
+	return v
+}
+
+// RefundResponse 退款结果
+type RefundResponse struct {
+	OrderID     string      // 原商户订单号
+	RefundID    string      // 商户退款单号
+	RefundNO    string      // 平台退款流水号
+	Amount      Amount      // 退款金额
+	RefundState RefundState // 退款状态
+	Outcome     Outcome     // 退款结果，为 OutcomePending 时应通过 RefundQuery 继续查询
+	RetCode     string      // 网关返回码
+}
+
+// Refund 退款（支持全额退款和部分退款）
+func (c *Client) Refund(ctx context.Context, req *RefundRequest) (*RefundResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	ret, err := c.Do(ctx, serviceRefund, req.bizData())
+	if err != nil {
+		return nil, err
+	}
+
+	done, err := c.checkOutcome(ret)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseAmount(ret.Get("refund_amount"))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &RefundResponse{
+		OrderID:     ret.Get("order_id"),
+		RefundID:    ret.Get("refund_no"),
+		RefundNO:    ret.Get("refund_trace"),
+		Amount:      amount,
+		RefundState: ParseRefundState(ret.Get("refund_state")),
+		RetCode:     ret.Get("ret_code"),
+	}
+
+	if done {
+		resp.Outcome = resp.RefundState.Outcome()
+	}
+
+	return resp, nil
+}
+
+// ErrOrderNotRefundable 订单不可退款（非支付成功状态）
+var ErrOrderNotRefundable = errors.New("order is not refundable")
+
+// RefundFull 全额退款；先查询原订单，校验订单已支付成功，再以实际支付金额及平台交易号发起退款；
+// 订单不是支付成功状态时返回的错误满足 errors.Is(err, ErrOrderNotRefundable)，不会发起退款
+func (c *Client) RefundFull(ctx context.Context, orderID, refundOrderID string) (*RefundResponse, error) {
+	if len(refundOrderID) == 0 {
+		return nil, errors.New("refund_no is required")
+	}
+
+	order, err := c.QueryOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != TradeSuccess {
+		return nil, fmt.Errorf("%w: order_id = %s, trade_state = %s", ErrOrderNotRefundable, orderID, order.Status)
+	}
+
+	req := &RefundRequest{
+		OrderID:   orderID,
+		RefundID:  refundOrderID,
+		Amount:    order.Amount,
+		OrgAmount: order.Amount,
+		TradeNO:   order.TradeNO,
+	}
+
+	return c.Refund(ctx, req)
+}
+
+const serviceRefundQuery = "mer_refund_query"
+
+// RefundState 退款状态
+type RefundState int
+
+const (
+	RefundUnknown    RefundState = iota // 未知状态
+	RefundProcessing                    // 退款中：REFUND_PROCESS
+	RefundSuccess                       // 退款成功：REFUND_SUCCESS
+	RefundFail                          // 退款失败：REFUND_FAIL
+)
+
+var refundStateText = map[RefundState]string{
+	RefundUnknown:    "UNKNOWN",
+	RefundProcessing: "REFUND_PROCESS",
+	RefundSuccess:    "REFUND_SUCCESS",
+	RefundFail:       "REFUND_FAIL",
+}
+
+// String 返回网关的状态码
+func (s RefundState) String() string {
+	if v, ok := refundStateText[s]; ok {
+		return v
+	}
+
+	return refundStateText[RefundUnknown]
+}
+
+// ParseRefundState 解析网关返回的退款状态
+func ParseRefundState(s string) RefundState {
+	for k, v := range refundStateText {
+		if v == s {
+			return k
+		}
+	}
+
+	return RefundUnknown
+}
+
+// RefundStatus 退款状态查询结果
+type RefundStatus struct {
+	OrderID  string      // 原商户订单号
+	RefundID string      // 商户退款单号
+	State    RefundState // 退款状态
+	Amount   Amount      // 退款金额
+	SubCode  string      // 退款失败时的错误码，用于区分可重试与不可重试的失败
+	SubMsg   string      // 退款失败时的错误描述
+	Outcome  Outcome     // 退款结果，为 OutcomePending 时应继续查询
+	RetCode  string      // 网关返回码
+}
+
+// RefundQuery 退款查询；设置 WithQueryCoalescing 后，并发的相同查询共享一次网关请求
+func (c *Client) RefundQuery(ctx context.Context, refundOrderID string) (*RefundStatus, error) {
+	if len(refundOrderID) == 0 {
+		return nil, errors.New("refund_no is required")
+	}
+
+	ret, err := c.doQuery(ctx, serviceRefundQuery, refundOrderID, V{"refund_no": refundOrderID})
+	if err != nil {
+		return nil, err
+	}
+
+	done, err := c.checkOutcome(ret)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseAmount(ret.Get("refund_amount"))
+	if err != nil {
+		return nil, err
+	}
+
+	status := &RefundStatus{
+		OrderID:  ret.Get("order_id"),
+		RefundID: ret.Get("refund_no"),
+		State:    ParseRefundState(ret.Get("refund_state")),
+		Amount:   amount,
+		RetCode:  ret.Get("ret_code"),
+	}
+
+	if status.State == RefundFail {
+		status.SubCode = ret.Get("refund_err_code")
+		status.SubMsg = ret.Get("refund_err_msg")
+	}
+
+	if done {
+		status.Outcome = status.State.Outcome()
+	}
+
+	return status, nil
+}
@@ -0,0 +1,141 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundRequest(t *testing.T) {
+	req := &RefundRequest{
+		OrderID:   "202312010001",
+		RefundID:  "R202312010001",
+		Amount:    50,
+		OrgAmount: 100,
+		Reason:    "test",
+	}
+	assert.Nil(t, req.validate())
+	assert.Equal(t, "order_id=202312010001&org_amount=100&refund_amount=50&refund_desc=test&refund_no=R202312010001", req.bizData().Encode("=", "&", WithEmptyMode(EmptyIgnore)))
+
+	req.Amount = 100
+	assert.Nil(t, req.validate())
+
+	req.Amount = 101
+	assert.EqualError(t, req.validate(), "refund_amount exceeds the original paid amount")
+
+	// 未提供原订单金额时不校验
+	req.OrgAmount = 0
+	assert.Nil(t, req.validate())
+	assert.Equal(t, "order_id=202312010001&refund_amount=101&refund_desc=test&refund_no=R202312010001", req.bizData().Encode("=", "&", WithEmptyMode(EmptyIgnore)))
+}
+
+func TestRefund(t *testing.T) {
+	gateway := newTestClient(t)
+
+	var (
+		form  V
+		reply V
+	)
+
+	mock := &mockHTTPClient{fn: func(n int, body []byte) (*http.Response, error) {
+		form, _ = ParseV(string(body))
+
+		html, _ := gateway.ReplyHTML(reply)
+		return mockResponse(http.StatusOK, html), nil
+	}}
+
+	cli := newTestClient(t, WithHTTPClient(mock))
+
+	req := &RefundRequest{
+		OrderID:   "202312010001",
+		RefundID:  "R202312010001",
+		Amount:    50,
+		OrgAmount: 100,
+		Reason:    "test",
+	}
+
+	reply = V{"ret_code": OK, "order_id": "202312010001", "refund_no": "R202312010001", "refund_trace": "3312010001", "refund_amount": "50", "refund_state": "REFUND_SUCCESS"}
+
+	resp, err := cli.Refund(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, serviceRefund, form.Get("service"))
+	assert.Equal(t, "50", form.Get("refund_amount"))
+	assert.Equal(t, "100", form.Get("org_amount"))
+	assert.Equal(t, "R202312010001", resp.RefundID)
+	assert.Equal(t, "3312010001", resp.RefundNO)
+	assert.Equal(t, Amount(50), resp.Amount)
+	assert.Equal(t, RefundSuccess, resp.RefundState)
+	assert.Equal(t, OutcomeSuccess, resp.Outcome)
+	assert.Equal(t, 1, mock.calls)
+
+	// 业务错误
+	reply = V{"ret_code": "00060780", "ret_msg": "refund amount exceeds"}
+
+	_, err = cli.Refund(context.Background(), req)
+
+	var respErr *ResponseError
+	assert.True(t, errors.As(err, &respErr))
+	assert.Equal(t, "00060780", respErr.Code)
+	assert.Equal(t, 2, mock.calls)
+
+	// 退款金额超过原订单金额，不发送请求
+	req.Amount = 101
+
+	_, err = cli.Refund(context.Background(), req)
+	assert.EqualError(t, err, "refund_amount exceeds the original paid amount")
+	assert.Equal(t, 2, mock.calls)
+}
+
+func TestRefundState(t *testing.T) {
+	assert.Equal(t, RefundProcessing, ParseRefundState("REFUND_PROCESS"))
+	assert.Equal(t, RefundFail, ParseRefundState("REFUND_FAIL"))
+	assert.Equal(t, RefundUnknown, ParseRefundState(""))
+	assert.Equal(t, "REFUND_SUCCESS", RefundSuccess.String())
+}
+
+func TestRefundFull(t *testing.T) {
+	cli := newTestClient(t)
+
+	state := "TRADE_SUCCESS"
+
+	var refundForm V
+
+	cli.httpCli = &mockHTTPClient{
+		fn: func(n int, b []byte) (*http.Response, error) {
+			form, _ := ParseV(string(b))
+
+			var ret V
+
+			switch form.Get("service") {
+			case serviceQueryOrder:
+				ret = V{"ret_code": OK, "order_id": form.Get("order_id"), "trade_no": "3312010001", "trade_state": state, "amount": "10000"}
+			case serviceRefund:
+				refundForm = form
+				ret = V{"ret_code": OK, "order_id": form.Get("order_id"), "refund_no": form.Get("refund_no"), "refund_amount": form.Get("refund_amount"), "refund_state": "REFUND_PROCESS"}
+			}
+
+			body, _ := cli.ReplyHTML(ret)
+
+			return mockResponse(http.StatusOK, body), nil
+		},
+	}
+
+	resp, err := cli.RefundFull(context.Background(), "202312010001", "R202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(10000), resp.Amount)
+	assert.Equal(t, RefundProcessing, resp.RefundState)
+	assert.Equal(t, "3312010001", refundForm.Get("trade_no"))
+	assert.Equal(t, "10000", refundForm.Get("org_amount"))
+
+	// 订单未支付，不发起退款
+	state = "WAIT_BUYER_PAY"
+	refundForm = nil
+
+	_, err = cli.RefundFull(context.Background(), "202312010001", "R202312010002")
+	assert.True(t, errors.Is(err, ErrOrderNotRefundable))
+	assert.EqualError(t, err, "order is not refundable: order_id = 202312010001, trade_state = WAIT_BUYER_PAY")
+	assert.Nil(t, refundForm)
+}
@@ -0,0 +1,35 @@
+package soopay
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID 在 Context 中设置请求ID，Do 请求时记录到日志（request_id），
+// 并在通过 WithRequestIDHeader 设置请求头名称时随请求发送，用于关联商户与平台的日志
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext 返回 Context 中的请求ID，未设置时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID 生成 UUID v4 格式的请求ID
+func newRequestID() string {
+	var b [16]byte
+
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant RFC 4122
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
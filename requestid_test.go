@@ -0,0 +1,74 @@
+package soopay
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	mock := &mockHTTPClient{
+		fn: func(n int, body []byte) (*http.Response, error) {
+			return mockResponse(http.StatusBadRequest, ""), nil
+		},
+	}
+
+	logger := new(testLogger)
+
+	cli := newTestClient(t, WithStructuredLogger(logger), WithRequestIDHeader("X-Request-ID"))
+	cli.httpCli = mock
+
+	ctx := WithRequestID(context.Background(), "req-001")
+	assert.Equal(t, "req-001", RequestIDFromContext(ctx))
+
+	_, err := cli.Do(ctx, "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "req-001", logger.responses[0].Map()["request_id"])
+	assert.Equal(t, "req-001", mock.header.Get("X-Request-ID"))
+
+	// 未设置时自动生成
+	_, err = cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+
+	id := logger.responses[1].Map()["request_id"]
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+	assert.Equal(t, id, mock.header.Get("X-Request-ID"))
+}
+
+type traceHeaderKey struct{}
+
+func TestWithContextHeaders(t *testing.T) {
+	mock := &mockHTTPClient{
+		fn: func(n int, body []byte) (*http.Response, error) {
+			return mockResponse(http.StatusBadRequest, ""), nil
+		},
+	}
+
+	extract := func(ctx context.Context) http.Header {
+		h, _ := ctx.Value(traceHeaderKey{}).(http.Header)
+		return h
+	}
+
+	cli := newTestClient(t, WithHTTPClient(mock), WithRequestHeader("X-B3-TraceId", "static"), WithContextHeaders(extract, "x-b3-traceid", "X-B3-SpanId"))
+
+	ctx := context.WithValue(context.Background(), traceHeaderKey{}, http.Header{
+		"X-B3-Traceid": {"463ac35c9f6413ad"},
+		"X-B3-Spanid":  {"a2fb4a1d1a96d312"},
+		"Cookie":       {"session=secret"},
+	})
+
+	_, err := cli.Do(ctx, "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "463ac35c9f6413ad", mock.header.Get("X-B3-TraceId"))
+	assert.Equal(t, "a2fb4a1d1a96d312", mock.header.Get("X-B3-SpanId"))
+	assert.Empty(t, mock.header.Get("Cookie"))
+
+	// Context 中未携带时，保留 WithRequestHeader 设置的请求头
+	_, err = cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "static", mock.header.Get("X-B3-TraceId"))
+	assert.Empty(t, mock.header.Get("X-B3-SpanId"))
+}
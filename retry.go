@@ -0,0 +1,133 @@
+package soopay
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// maxRetryBackoff 重试等待时间的上限
+const maxRetryBackoff = 30 * time.Second
+
+// retryableServices 允许重试的服务（只读的查询请求）；
+// 会改变资金或订单状态的请求（如：付款、退款、下单）不重试，以免请求已到达网关但连接中断时重复执行
+var retryableServices = map[string]struct{}{
+	serviceQueryOrder:    {},
+	serviceRefundQuery:   {},
+	serviceTransferQuery: {},
+	serviceQueryBalance:  {},
+	serviceDownloadBill:  {},
+}
+
+// retryable 判断服务是否允许重试
+func (c *Client) retryable(service string) bool {
+	if _, ok := retryableServices[service]; ok {
+		return true
+	}
+
+	_, ok := c.retryServices[service]
+
+	return ok
+}
+
+// retryDelay 第 attempt 次请求失败后的等待时间：按指数递增，不超过 maxRetryBackoff，并在 [d/2, d) 内随机抖动，避免并发请求同时重试
+func (c *Client) retryDelay(attempt int) time.Duration {
+	d := c.retryBackoff
+	for i := 1; i < attempt && d < maxRetryBackoff; i++ {
+		d <<= 1
+	}
+
+	if d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+
+	if half := d / 2; half > 0 {
+		d = half + time.Duration(rand.Int63n(int64(half)))
+	}
+
+	return d
+}
+
+// retryableStatus 判断HTTP状态码是否可重试
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// send 发送请求；若设置了重试，则只读的查询请求在连接错误或可重试的HTTP状态码时按指数退避重试，
+// 业务层面的失败（HTTP 200）不会重试；表单在重试前已完成签名，重试时不会重新签名
+func (c *Client) send(ctx context.Context, service string, body []byte, log *ReqLog, options ...HTTPOption) (*http.Response, error) {
+	maxAttempts := c.retryMax
+	if maxAttempts < 1 || !c.retryable(service) {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		log.SetAttempts(attempt)
+
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
+
+				return nil, err
+			}
+		}
+
+		// 熔断器只记录实际发送至网关的请求，限流等待失败不计入
+		if c.breaker != nil {
+			if err := c.breaker.allow(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.httpCli.Do(ctx, http.MethodPost, c.gateway, body, options...)
+
+		if c.breaker != nil {
+			c.breaker.done(ctx, resp, err)
+		}
+
+		if err != nil {
+			// 自定义的 HTTPClient 可能同时返回 Response 和错误
+			if resp != nil {
+				drainBody(resp.Body)
+				resp = nil
+			}
+
+			// 优先返回 Context 的错误
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+		} else if !retryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+
+		if attempt >= maxAttempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			drainBody(resp.Body)
+		}
+
+		timer := time.NewTimer(c.retryDelay(attempt))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// maxDrainBytes 关闭Body前最多丢弃的字节数，超出则直接关闭连接
+const maxDrainBytes = 4 << 10
+
+// drainBody 丢弃并关闭Body以便连接复用
+func drainBody(body io.ReadCloser) {
+	io.CopyN(io.Discard, body, maxDrainBytes)
+	body.Close()
+}
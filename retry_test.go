@@ -0,0 +1,317 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHTTPClient struct {
+	calls  int
+	header http.Header
+	fn     func(n int, body []byte) (*http.Response, error)
+}
+
+func (m *mockHTTPClient) Do(ctx context.Context, method, reqURL string, body []byte, options ...HTTPOption) (*http.Response, error) {
+	m.calls++
+
+	opts := &httpOptions{header: http.Header{}}
+	for _, f := range options {
+		f(opts)
+	}
+	m.header = opts.header
+
+	return m.fn(m.calls, body)
+}
+
+func mockResponse(code int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestRetry(t *testing.T) {
+	mock := &mockHTTPClient{
+		fn: func(n int, body []byte) (*http.Response, error) {
+			switch n {
+			case 1:
+				return nil, errors.New("connection reset by peer")
+			case 2:
+				return mockResponse(http.StatusServiceUnavailable, ""), nil
+			}
+
+			return mockResponse(http.StatusOK, "ok"), nil
+		},
+	}
+
+	cli := NewClient("10001", WithRetry(3, time.Millisecond))
+	cli.httpCli = mock
+
+	log := NewReqLog(http.MethodPost, cli.gateway)
+
+	resp, err := cli.send(context.Background(), serviceQueryOrder, []byte("a=b"), log)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, mock.calls)
+	assert.Equal(t, 3, log.Entry().Attempts)
+
+	// 超过最大请求次数
+	mock.calls = 0
+
+	cli = NewClient("10001", WithRetry(2, time.Millisecond))
+	cli.httpCli = mock
+
+	_, err = cli.send(context.Background(), serviceQueryOrder, []byte("a=b"), NewReqLog(http.MethodPost, cli.gateway))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, mock.calls)
+
+	// HTTP 200 不重试
+	mock.calls = 2
+
+	_, err = cli.send(context.Background(), serviceQueryOrder, []byte("a=b"), NewReqLog(http.MethodPost, cli.gateway))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, mock.calls)
+}
+
+func TestRetryServices(t *testing.T) {
+	mock := &mockHTTPClient{
+		fn: func(n int, body []byte) (*http.Response, error) {
+			return nil, errors.New("connection reset by peer")
+		},
+	}
+
+	cli := NewClient("10001", WithRetry(3, time.Millisecond), WithRetryServices("custom_query"))
+	cli.httpCli = mock
+
+	// 改变资金状态的请求不重试
+	for _, service := range []string{serviceTransfer, serviceRefund, serviceUnifiedOrder, "custom_pay"} {
+		mock.calls = 0
+
+		_, err := cli.send(context.Background(), service, []byte("a=b"), NewReqLog(http.MethodPost, cli.gateway))
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, mock.calls, service)
+	}
+
+	for _, service := range []string{serviceRefundQuery, "custom_query"} {
+		mock.calls = 0
+
+		_, err := cli.send(context.Background(), service, []byte("a=b"), NewReqLog(http.MethodPost, cli.gateway))
+		assert.NotNil(t, err)
+		assert.Equal(t, 3, mock.calls, service)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cli := NewClient("10001", WithRetry(20, time.Second))
+
+	for attempt := 1; attempt <= 20; attempt++ {
+		d := cli.retryDelay(attempt)
+
+		limit := time.Second << (attempt - 1)
+		if attempt > 5 {
+			limit = maxRetryBackoff
+		}
+
+		assert.GreaterOrEqual(t, d, limit/2)
+		assert.Less(t, d, limit)
+	}
+
+	cli = NewClient("10001", WithRetry(3, 0))
+	assert.Equal(t, time.Duration(0), cli.retryDelay(2))
+}
+
+func TestRetryContext(t *testing.T) {
+	mock := &mockHTTPClient{
+		fn: func(n int, body []byte) (*http.Response, error) {
+			return mockResponse(http.StatusBadGateway, ""), nil
+		},
+	}
+
+	cli := NewClient("10001", WithRetry(5, time.Second))
+	cli.httpCli = mock
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := cli.send(ctx, serviceQueryOrder, []byte("a=b"), NewReqLog(http.MethodPost, cli.gateway))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, mock.calls)
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	var deadline time.Time
+
+	cli := newTestClient(t, WithDefaultTimeout(time.Minute))
+	cli.httpCli = httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		deadline, _ = ctx.Deadline()
+		return mockResponse(http.StatusBadRequest, ""), nil
+	})
+
+	// 未设置截止时间
+	start := time.Now()
+
+	_, err := cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+	assert.WithinDuration(t, start.Add(time.Minute), deadline, time.Second)
+
+	// 已设置更短的截止时间
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	expected, _ := ctx.Deadline()
+
+	_, err = cli.Do(ctx, "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+	assert.Equal(t, expected, deadline)
+}
+
+type httpClientFunc func(ctx context.Context) (*http.Response, error)
+
+func (f httpClientFunc) Do(ctx context.Context, method, reqURL string, body []byte, options ...HTTPOption) (*http.Response, error) {
+	return f(ctx)
+}
+
+// trackBody 记录Body是否已关闭；ctx 不为 nil 时，读取阻塞至 ctx 结束
+type trackBody struct {
+	ctx    context.Context
+	r      io.Reader
+	closed int32
+}
+
+func (b *trackBody) Read(p []byte) (int, error) {
+	if b.ctx != nil {
+		<-b.ctx.Done()
+		return 0, errors.New("read: connection reset")
+	}
+
+	return b.r.Read(p)
+}
+
+func (b *trackBody) Close() error {
+	atomic.StoreInt32(&b.closed, 1)
+	return nil
+}
+
+func (b *trackBody) isClosed() bool {
+	return atomic.LoadInt32(&b.closed) == 1
+}
+
+func TestDoContextCanceled(t *testing.T) {
+	cli := newTestClient(t, WithRetry(3, time.Second))
+
+	// 请求中取消
+	cli.httpCli = httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := cli.Do(ctx, "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// 读取返回报文时取消
+	var body *trackBody
+
+	cli.httpCli = httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		body = &trackBody{ctx: ctx}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: body}, nil
+	})
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err = cli.Do(ctx, "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, body.isClosed())
+
+	// 重试等待时超时，已返回的Body均已关闭
+	bodies := make([]*trackBody, 0)
+
+	cli.httpCli = httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		b := &trackBody{r: strings.NewReader("busy")}
+		bodies = append(bodies, b)
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: b}, nil
+	})
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = cli.Do(ctx, "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, len(bodies))
+	assert.True(t, bodies[0].isClosed())
+
+	// 自定义 HTTPClient 同时返回 Response 和错误
+	cli = newTestClient(t)
+	cli.httpCli = httpClientFunc(func(ctx context.Context) (*http.Response, error) {
+		body = &trackBody{r: strings.NewReader("")}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: body}, errors.New("proxy error")
+	})
+
+	_, err = cli.Do(context.Background(), "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.EqualError(t, err, "proxy error")
+	assert.True(t, body.isClosed())
+}
+
+func TestDoContextCanceledServer(t *testing.T) {
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cli := newTestClient(t, WithGateway(srv.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := cli.Do(ctx, "mer_order_info_query", V{"order_id": "202312010001"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimit(t *testing.T) {
+	mock := &mockHTTPClient{
+		fn: func(n int, body []byte) (*http.Response, error) {
+			return mockResponse(http.StatusOK, "ok"), nil
+		},
+	}
+
+	cli := NewClient("10001", WithRateLimit(10, 1))
+	cli.httpCli = mock
+
+	// 突发数为1，第二个请求需等待约 100ms
+	start := time.Now()
+
+	for i := 0; i < 2; i++ {
+		_, err := cli.send(context.Background(), serviceQueryOrder, []byte("a=b"), NewReqLog(http.MethodPost, cli.gateway))
+		assert.Nil(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+
+	// 等待期间 Context 取消
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := cli.send(ctx, serviceQueryOrder, []byte("a=b"), NewReqLog(http.MethodPost, cli.gateway))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, mock.calls)
+
+	assert.Panics(t, func() { WithRateLimit(0, 1) })
+}
@@ -0,0 +1,158 @@
+package soopay
+
+import (
+	"crypto"
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 签名方式（参数 sign_type 的值）
+const (
+	SignRSA = "RSA"
+	SignMD5 = "MD5"
+)
+
+// Signer 签名器，用于请求签名及返回数据验签
+type Signer interface {
+	// SignType 签名方式，即参数 sign_type 的值，如：RSA、MD5
+	SignType() string
+
+	// Sign 对待签名串签名，返回参数 sign 的值
+	Sign(data []byte) (string, error)
+
+	// Verify 验证待签名串的签名
+	Verify(data []byte, sign string) error
+}
+
+// ErrMalformedSignature 签名（参数 sign 的值）为空或不是合法的 Base64 编码
+var ErrMalformedSignature = errors.New("malformed signature encoding")
+
+// rsaSigner RSA签名（默认），使用 Client 配置的商户私钥、平台公钥及哈希算法
+type rsaSigner struct {
+	c *Client
+}
+
+func (s *rsaSigner) SignType() string {
+	return SignRSA
+}
+
+func (s *rsaSigner) Sign(data []byte) (string, error) {
+	return s.signHash(s.c.signHash, data)
+}
+
+// signHash 使用指定的哈希算法签名
+func (s *rsaSigner) signHash(hash crypto.Hash, data []byte) (string, error) {
+	if s.c.prvKey == nil {
+		return "", ErrNoPrivateKey
+	}
+
+	sign, err := s.c.prvKey.Sign(hash, data)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(sign), nil
+}
+
+func (s *rsaSigner) Verify(data []byte, sign string) error {
+	_, err := s.verifyKey(data, sign)
+	return err
+}
+
+// verifyKey 依次使用各平台公钥验签，返回验签通过的公钥
+func (s *rsaSigner) verifyKey(data []byte, sign string) (*PublicKey, error) {
+	if len(s.c.pubKeys) == 0 {
+		return nil, ErrNoPublicKey
+	}
+
+	// 先校验签名的编码，避免返回含义模糊的验签错误
+	b, err := base64.StdEncoding.DecodeString(sign)
+	if err != nil || len(b) == 0 {
+		return nil, ErrMalformedSignature
+	}
+
+	names := make([]string, 0, len(s.c.verifyHashes))
+	for _, hash := range s.c.verifyHashes {
+		names = append(names, hash.String())
+	}
+
+	fingerprints := make([]string, 0, len(s.c.pubKeys))
+
+	for _, key := range s.c.pubKeys {
+		for _, hash := range s.c.verifyHashes {
+			if err = key.Verify(hash, data, b); err == nil {
+				return key, nil
+			}
+		}
+
+		fingerprints = append(fingerprints, shortFingerprint(key))
+	}
+
+	if len(fingerprints) > 1 {
+		return nil, fmt.Errorf("%w (tried %s; public keys %s)", err, strings.Join(names, ", "), strings.Join(fingerprints, ", "))
+	}
+
+	return nil, fmt.Errorf("%w (tried %s; public key %s)", err, strings.Join(names, ", "), fingerprints[0])
+}
+
+// shortFingerprint 公钥指纹的前16位，用于错误信息
+func shortFingerprint(key *PublicKey) string {
+	return key.Fingerprint()[:16]
+}
+
+type md5Signer struct {
+	key string
+}
+
+// NewMD5Signer 生成MD5签名器（适用于仍使用MD5签名的商户）；
+// 签名规则：md5(待签名串 + "&key=" + 密钥)，结果为小写十六进制字符串
+func NewMD5Signer(key string) Signer {
+	return &md5Signer{key: key}
+}
+
+func (s *md5Signer) SignType() string {
+	return SignMD5
+}
+
+func (s *md5Signer) Sign(data []byte) (string, error) {
+	h := md5.New()
+	h.Write(data)
+	h.Write([]byte("&key="))
+	h.Write([]byte(s.key))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (s *md5Signer) Verify(data []byte, sign string) error {
+	expected, _ := s.Sign(data)
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(sign))) != 1 {
+		return errors.New("md5: verification error")
+	}
+
+	return nil
+}
+
+type nopSigner struct{}
+
+// NewNopSigner 生成空签名器，签名固定为「TEST」且验签总是通过；仅用于 WithTestMode
+func NewNopSigner() Signer {
+	return nopSigner{}
+}
+
+func (nopSigner) SignType() string {
+	return SignRSA
+}
+
+func (nopSigner) Sign(data []byte) (string, error) {
+	return "TEST", nil
+}
+
+func (nopSigner) Verify(data []byte, sign string) error {
+	return nil
+}
@@ -0,0 +1,231 @@
+package soopay
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMD5Signer(t *testing.T) {
+	signer := NewMD5Signer("secret")
+
+	sign, err := signer.Sign([]byte("amount=100&order_id=202312010001"))
+	assert.Nil(t, err)
+	assert.Equal(t, 32, len(sign))
+	assert.Nil(t, signer.Verify([]byte("amount=100&order_id=202312010001"), sign))
+	assert.NotNil(t, signer.Verify([]byte("amount=101&order_id=202312010001"), sign))
+	assert.NotNil(t, NewMD5Signer("other").Verify([]byte("amount=100&order_id=202312010001"), sign))
+}
+
+func TestWithSignType(t *testing.T) {
+	// MD5 签名无需商户私钥
+	cli := NewClient("10001", WithSignType(NewMD5Signer("secret")))
+
+	form, err := cli.BuildForm("mer_order_info_query", V{"order_id": "202312010001"})
+	assert.Nil(t, err)
+
+	vals, err := url.ParseQuery(form)
+	assert.Nil(t, err)
+	assert.Equal(t, SignMD5, vals.Get("sign_type"))
+
+	ret, err := cli.VerifyQuery(vals)
+	assert.Nil(t, err)
+	assert.Equal(t, "202312010001", ret.Get("order_id"))
+
+	// 根据返回数据的 sign_type 选择验签方式
+	rsaCli := newTestClient(t)
+
+	rsaForm, err := rsaCli.BuildForm("mer_order_info_query", V{"order_id": "202312010001"})
+	assert.Nil(t, err)
+
+	rsaVals, err := url.ParseQuery(rsaForm)
+	assert.Nil(t, err)
+	assert.Equal(t, SignRSA, rsaVals.Get("sign_type"))
+
+	cli = newTestClient(t, WithSignType(NewMD5Signer("secret")))
+
+	_, err = cli.VerifyQuery(rsaVals)
+	assert.Nil(t, err)
+
+	_, err = cli.VerifyQuery(vals)
+	assert.Nil(t, err)
+
+	vals.Set("sign_type", "SM2")
+
+	_, err = cli.VerifyQuery(vals)
+	assert.EqualError(t, err, `unsupported sign_type "SM2"`)
+}
+
+func TestWithTestMode(t *testing.T) {
+	cli := NewClient("10001", WithGateway("http://127.0.0.1:8080/payservice.do"), WithTestMode(NewNopSigner()))
+
+	form, err := cli.BuildForm("mer_order_info_query", V{"order_id": "202312010001"})
+	assert.Nil(t, err)
+
+	vals, err := url.ParseQuery(form)
+	assert.Nil(t, err)
+	assert.Equal(t, "TEST", vals.Get("sign"))
+
+	// 忽略返回数据的 sign_type，验签总是通过
+	ret, err := cli.VerifyQuery(url.Values{"ret_code": {OK}, "sign_type": {SignMD5}, "sign": {"xxx"}})
+	assert.Nil(t, err)
+	assert.Equal(t, OK, ret.Get("ret_code"))
+
+	// 生产环境网关不允许开启测试模式
+	assert.Panics(t, func() { NewClient("10001", WithTestMode(NewNopSigner())) })
+	assert.Panics(t, func() {
+		NewClient("10001", WithGateway("https://PAY.soopay.net/spay/pay/payservice.do"), WithTestMode(NewNopSigner()))
+	})
+	assert.Panics(t, func() { NewClient("10001", WithGateway("http://127.0.0.1:8080"), WithTestMode(nil)) })
+}
+
+func TestVerifySign(t *testing.T) {
+	cli := NewClient("10001", WithSignType(NewMD5Signer("secret")))
+
+	form, err := cli.BuildForm("mer_order_info_query", V{"order_id": "202312010001", "remark": ""})
+	assert.Nil(t, err)
+
+	data, err := ParseV(form)
+	assert.Nil(t, err)
+	assert.Nil(t, cli.VerifySign(data))
+
+	// 默认空值字段参与验签
+	data.Set("extra", "")
+	assert.NotNil(t, cli.VerifySign(data))
+
+	// EmptyIgnore 模式下，不参与签名的空值字段不影响验签
+	cli = NewClient("10001", WithSignType(NewMD5Signer("secret")), WithVerifyEmptyMode(EmptyIgnore))
+	assert.Nil(t, cli.VerifySign(data))
+
+	data.Set("order_id", "202312010002")
+	assert.NotNil(t, cli.VerifySign(data))
+
+	data.Del("sign_type")
+	data.Set("order_id", "202312010001")
+	assert.Nil(t, cli.VerifySign(data))
+}
+
+func TestWithPublicKeys(t *testing.T) {
+	gateway := newTestClient(t)
+
+	vals, err := url.ParseQuery(mustBuildForm(t, gateway, V{"order_id": "202312010001"}))
+	assert.Nil(t, err)
+
+	pubKey, err := NewPublicKeyFromPem(testPublicKey)
+	assert.Nil(t, err)
+
+	other, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Nil(t, err)
+
+	oldKey := &PublicKey{key: &other.PublicKey}
+
+	// 轮换期间，任一公钥验签通过即可
+	cli := NewClient("10001", WithPublicKeys(oldKey, pubKey))
+
+	ret, key, err := cli.VerifyQueryPublicKey(vals)
+	assert.Nil(t, err)
+	assert.Equal(t, "202312010001", ret.Get("order_id"))
+	assert.Equal(t, pubKey.Fingerprint(), key.Fingerprint())
+
+	// 加密使用第一个公钥
+	assert.Same(t, oldKey, cli.pubKey)
+
+	cli = NewClient("10001", WithPublicKey(oldKey))
+
+	_, err = cli.VerifyQuery(vals)
+	assert.EqualError(t, err, "crypto/rsa: verification error (tried SHA-256, SHA-1; public key "+shortFingerprint(oldKey)+")")
+
+	cli = NewClient("10001", WithPublicKeys(oldKey, oldKey))
+
+	_, err = cli.VerifyQuery(vals)
+	assert.ErrorContains(t, err, "(tried SHA-256, SHA-1; public keys "+shortFingerprint(oldKey)+", "+shortFingerprint(oldKey)+")")
+
+	// 非RSA签名不返回公钥
+	md5Cli := NewClient("10001", WithSignType(NewMD5Signer("secret")))
+
+	md5Vals, err := url.ParseQuery(mustBuildForm(t, md5Cli, V{"order_id": "202312010001"}))
+	assert.Nil(t, err)
+
+	_, key, err = md5Cli.VerifyQueryPublicKey(md5Vals)
+	assert.Nil(t, err)
+	assert.Nil(t, key)
+}
+
+func mustBuildForm(t *testing.T, cli *Client, data V) string {
+	form, err := cli.BuildForm(serviceQueryOrder, data)
+	assert.Nil(t, err)
+
+	return form
+}
+
+func TestVerifyQueryDuplicateKey(t *testing.T) {
+	cli := newTestClient(t)
+
+	vals, err := url.ParseQuery(mustBuildForm(t, cli, V{"order_id": "202312010001", "amount": "100"}))
+	assert.Nil(t, err)
+
+	_, err = cli.VerifyQuery(vals)
+	assert.Nil(t, err)
+
+	// 重复的 sign 字段
+	dup, err := url.ParseQuery(mustBuildForm(t, cli, V{"order_id": "202312010001", "amount": "100"}) + "&sign=xxx")
+	assert.Nil(t, err)
+
+	_, err = cli.VerifyQuery(dup)
+	assert.EqualError(t, err, `duplicate key "sign"`)
+
+	// 重复的业务字段
+	vals.Add("amount", "10000")
+
+	_, _, err = cli.VerifyQueryKeys(vals)
+	assert.EqualError(t, err, `duplicate key "amount"`)
+}
+
+func TestWithSignKeys(t *testing.T) {
+	cli := newTestClient(t)
+
+	data, err := ParseV(mustBuildForm(t, cli, V{"order_id": "202312010001"}))
+	assert.Nil(t, err)
+
+	// 签名字段名为 SIGN、SIGN_TYPE 的通知
+	data.Set("SIGN", data.Get("sign"))
+	data.Set("SIGN_TYPE", data.Get("sign_type"))
+	data.Del("sign")
+	data.Del("sign_type")
+
+	assert.NotNil(t, cli.VerifySign(data))
+
+	cli = newTestClient(t, WithSignKeys("SIGN", "SIGN_TYPE"))
+	assert.Nil(t, cli.VerifySign(data))
+
+	vals := url.Values{}
+	for k, v := range data {
+		vals.Set(k, v)
+	}
+
+	_, keys, err := cli.VerifyQueryKeys(vals)
+	assert.Nil(t, err)
+	assert.NotContains(t, keys, "SIGN")
+	assert.NotContains(t, keys, "SIGN_TYPE")
+
+	assert.Panics(t, func() { WithSignKeys("", "sign_type") })
+}
+
+func TestMalformedSignature(t *testing.T) {
+	cli := newTestClient(t)
+
+	vals, err := url.ParseQuery(mustBuildForm(t, cli, V{"order_id": "202312010001"}))
+	assert.Nil(t, err)
+
+	for _, sign := range []string{"", "not base64!"} {
+		vals.Set("sign", sign)
+
+		_, err = cli.VerifyQuery(vals)
+		assert.True(t, errors.Is(err, ErrMalformedSignature))
+		assert.EqualError(t, err, "malformed signature encoding")
+	}
+}
@@ -0,0 +1,23 @@
+package soopay
+
+import "context"
+
+// Tracer 链路追踪，每次 Do 请求时创建 Span，并通过返回的 Context 传递给 HTTPClient；
+// OpenTelemetry 的实现见子模块 github.com/shenghui0779/soopay-go/otel
+type Tracer interface {
+	// Start 开始 Span，service 为网关服务名（参数 service 的值）
+	Start(ctx context.Context, service string) (context.Context, Span)
+}
+
+// Span 一次网关请求
+type Span interface {
+	// End 结束 Span；statusCode 为HTTP状态码（网络错误时为0），err 的类型同 Metrics
+	End(statusCode int, err error)
+}
+
+// WithTracer 设置链路追踪（默认不追踪）
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
@@ -0,0 +1,72 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type testSpan struct {
+	service    string
+	statusCode int
+	err        error
+	ended      bool
+}
+
+func (s *testSpan) End(statusCode int, err error) {
+	s.statusCode = statusCode
+	s.err = err
+	s.ended = true
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, service string) (context.Context, Span) {
+	span := &testSpan{service: service}
+	t.spans = append(t.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := new(testTracer)
+
+	cli := newTestClient(t, WithTracer(tracer))
+
+	body, err := cli.ReplyHTML(V{"ret_code": codeOrderNotFound, "ret_msg": "订单不存在"})
+	assert.Nil(t, err)
+
+	var propagated bool
+
+	cli.httpCli = HTTPClientFunc(func(ctx context.Context, method, reqURL string, b []byte, options ...HTTPOption) (*http.Response, error) {
+		_, propagated = ctx.Value(spanKey{}).(*testSpan)
+		return mockResponse(http.StatusOK, body), nil
+	})
+
+	_, err = cli.QueryOrder(context.Background(), "202312010001")
+	assert.True(t, errors.Is(err, ErrOrderNotFound))
+	assert.True(t, propagated)
+
+	span := tracer.spans[0]
+	assert.True(t, span.ended)
+	assert.Equal(t, serviceQueryOrder, span.service)
+	assert.Equal(t, http.StatusOK, span.statusCode)
+	assert.True(t, errors.Is(span.err, ErrOrderNotFound))
+
+	// 网络错误
+	cli.httpCli = HTTPClientFunc(func(ctx context.Context, method, reqURL string, b []byte, options ...HTTPOption) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	_, err = cli.Do(context.Background(), serviceQueryOrder, V{"order_id": "202312010001"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, tracer.spans[1].statusCode)
+	assert.EqualError(t, tracer.spans[1].err, "connection refused")
+}
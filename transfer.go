@@ -0,0 +1,242 @@
+package soopay
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const serviceTransfer = "transfer_direct_req"
+
+// TransferAccountType 收款账户类型
+type TransferAccountType string
+
+const (
+	TransferToBankCard TransferAccountType = "00" // 银行卡
+	TransferToUmpay    TransferAccountType = "02" // 联动账户
+)
+
+// TransferRequest 付款（提现）请求；收款账号和户名会自动加密，无需调用 Encrypt
+type TransferRequest struct {
+	OrderID     string              // 商户付款单号（必填）
+	MerDate     string              // 商户付款日期（格式：20060102），默认：当天
+	Amount      Amount              // 付款金额（必填）
+	AccountType TransferAccountType // 收款账户类型，默认：银行卡
+	AccountNO   string              // 收款账号（必填）
+	AccountName string              // 收款户名（必填）
+	BankName    string              // 收款银行名称
+	Purpose     string              // 付款用途
+	NotifyURL   string              // 异步通知地址
+}
+
+func (r *TransferRequest) validate() error {
+	if len(r.OrderID) == 0 {
+		return errors.New("order_id is required")
+	}
+
+	if r.Amount <= 0 {
+		return errors.New("amount must be greater than 0")
+	}
+
+	if len(r.AccountNO) == 0 {
+		return errors.New("recv_account is required")
+	}
+
+	if len(r.AccountName) == 0 {
+		return errors.New("recv_user_name is required")
+	}
+
+	return nil
+}
+
+func (r *TransferRequest) bizData(encrypt func(plain string) (string, error), now time.Time) (V, error) {
+	account, err := encrypt(r.AccountNO)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := encrypt(r.AccountName)
+	if err != nil {
+		return nil, err
+	}
+
+	merDate := r.MerDate
+	if len(merDate) == 0 {
+		merDate = now.In(gatewayLocation).Format("20060102")
+	}
+
+	accountType := r.AccountType
+	if len(accountType) == 0 {
+		accountType = TransferToBankCard
+	}
+
+	v := V{}
+
+	v.Set("order_id", r.OrderID)
+	v.Set("mer_date", merDate)
+	v.Set("amount", r.Amount.String())
+	v.Set("recv_account_type", string(accountType))
+	v.Set("recv_account", account)
+	v.Set("recv_user_name", name)
+	v.Set("recv_bank_name", r.BankName)
+	v.Set("purpose", r.Purpose)
+	v.Set("notify_url", r.NotifyURL)
+
+	return v, nil
+}
+
+// TransferState 付款状态
+type TransferState int
+
+const (
+	TransferUnknown    TransferState = iota // 未知状态
+	TransferProcessing                      // 付款中
+	TransferSuccess                         // 付款成功
+	TransferFail                            // 付款失败
+)
+
+var transferStateText = map[TransferState]string{
+	TransferUnknown:    "UNKNOWN",
+	TransferProcessing: "PROCESSING",
+	TransferSuccess:    "SUCCESS",
+	TransferFail:       "FAIL",
+}
+
+// 网关返回的付款状态码
+var transferStateCodes = map[string]TransferState{
+	"1":  TransferProcessing, // 支付中
+	"11": TransferProcessing, // 待确认
+	"12": TransferProcessing, // 已冻结，待财务审核
+	"14": TransferProcessing, // 财务已审核，待出款
+	"16": TransferProcessing, // 受理成功，交易处理中
+	"17": TransferProcessing, // 交易失败，退单中
+	"4":  TransferSuccess,    // 成功
+	"3":  TransferFail,       // 失败
+	"13": TransferFail,       // 待解冻，交易失败
+	"15": TransferFail,       // 财务审核失败，交易失败
+	"18": TransferFail,       // 交易失败，退单成功
+}
+
+// String 返回状态描述
+func (s TransferState) String() string {
+	if v, ok := transferStateText[s]; ok {
+		return v
+	}
+
+	return transferStateText[TransferUnknown]
+}
+
+// ParseTransferState 解析网关返回的付款状态码
+func ParseTransferState(s string) TransferState {
+	if v, ok := transferStateCodes[s]; ok {
+		return v
+	}
+
+	return TransferUnknown
+}
+
+// TransferResponse 付款结果
+type TransferResponse struct {
+	OrderID    string        // 商户付款单号
+	TransferNO string        // 平台付款流水号
+	Amount     Amount        // 付款金额
+	State      TransferState // 付款状态
+	Outcome    Outcome       // 付款结果，为 OutcomePending 时应通过 TransferQuery 继续查询
+	RetCode    string        // 网关返回码
+}
+
+// Transfer 付款（提现）至银行卡或联动账户
+func (c *Client) Transfer(ctx context.Context, req *TransferRequest) (*TransferResponse, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	bizData, err := req.bizData(c.Encrypt, c.now())
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := c.Do(ctx, serviceTransfer, bizData)
+	if err != nil {
+		return nil, err
+	}
+
+	done, err := c.checkOutcome(ret)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseAmount(ret.Get("amount"))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &TransferResponse{
+		OrderID:    ret.Get("order_id"),
+		TransferNO: ret.Get("trade_no"),
+		Amount:     amount,
+		State:      ParseTransferState(ret.Get("trade_state")),
+		RetCode:    ret.Get("ret_code"),
+	}
+
+	if done {
+		resp.Outcome = resp.State.Outcome()
+	}
+
+	return resp, nil
+}
+
+const serviceTransferQuery = "transfer_query"
+
+// TransferStatus 付款状态查询结果
+type TransferStatus struct {
+	OrderID    string        // 商户付款单号
+	TransferNO string        // 平台付款流水号
+	State      TransferState // 付款状态
+	Amount     Amount        // 付款金额
+	SubCode    string        // 付款失败时的错误码（如：银行拒绝的原因码）
+	SubMsg     string        // 付款失败时的错误描述
+	Outcome    Outcome       // 付款结果，为 OutcomePending 时应继续查询
+	RetCode    string        // 网关返回码
+}
+
+// TransferQuery 付款状态查询
+func (c *Client) TransferQuery(ctx context.Context, transferID string) (*TransferStatus, error) {
+	if len(transferID) == 0 {
+		return nil, errors.New("order_id is required")
+	}
+
+	ret, err := c.Do(ctx, serviceTransferQuery, V{"order_id": transferID})
+	if err != nil {
+		return nil, err
+	}
+
+	done, err := c.checkOutcome(ret)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseAmount(ret.Get("amount"))
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TransferStatus{
+		OrderID:    ret.Get("order_id"),
+		TransferNO: ret.Get("trade_no"),
+		State:      ParseTransferState(ret.Get("trade_state")),
+		Amount:     amount,
+		RetCode:    ret.Get("ret_code"),
+	}
+
+	if status.State == TransferFail {
+		status.SubCode = ret.Get("transfer_err_code")
+		status.SubMsg = ret.Get("transfer_err_msg")
+	}
+
+	if done {
+		status.Outcome = status.State.Outcome()
+	}
+
+	return status, nil
+}
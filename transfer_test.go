@@ -0,0 +1,84 @@
+package soopay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferRequest(t *testing.T) {
+	cli := newTestClient(t)
+
+	req := &TransferRequest{
+		OrderID:     "T202312010001",
+		MerDate:     "20231201",
+		Amount:      100,
+		AccountNO:   "6222020000000000000",
+		AccountName: "张三",
+		Purpose:     "提现",
+	}
+	assert.Nil(t, req.validate())
+
+	v, err := req.bizData(cli.Encrypt, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, "T202312010001", v.Get("order_id"))
+	assert.Equal(t, "20231201", v.Get("mer_date"))
+	assert.Equal(t, "100", v.Get("amount"))
+	assert.Equal(t, "00", v.Get("recv_account_type"))
+
+	// 敏感字段自动加密
+	assert.NotEqual(t, "6222020000000000000", v.Get("recv_account"))
+
+	account, err := cli.Decrypt(v.Get("recv_account"))
+	assert.Nil(t, err)
+	assert.Equal(t, "6222020000000000000", account)
+
+	name, err := cli.Decrypt(v.Get("recv_user_name"))
+	assert.Nil(t, err)
+	assert.Equal(t, "张三", name)
+
+	req.AccountName = ""
+	assert.EqualError(t, req.validate(), "recv_user_name is required")
+}
+
+func TestTransferState(t *testing.T) {
+	assert.Equal(t, TransferSuccess, ParseTransferState("4"))
+	assert.Equal(t, TransferFail, ParseTransferState("3"))
+	assert.Equal(t, TransferProcessing, ParseTransferState("16"))
+	assert.Equal(t, TransferUnknown, ParseTransferState(""))
+	assert.Equal(t, "SUCCESS", TransferSuccess.String())
+}
+
+func TestTransferQuery(t *testing.T) {
+	cli := newTestClient(t)
+
+	body, err := cli.ReplyHTML(V{
+		"ret_code":          OK,
+		"order_id":          "T202312010001",
+		"trade_no":          "3312010001",
+		"trade_state":       "3",
+		"amount":            "100",
+		"transfer_err_code": "00131040",
+		"transfer_err_msg":  "收款账户户名不符",
+	})
+	assert.Nil(t, err)
+
+	cli.httpCli = &mockHTTPClient{
+		fn: func(n int, b []byte) (*http.Response, error) {
+			return mockResponse(http.StatusOK, body), nil
+		},
+	}
+
+	status, err := cli.TransferQuery(context.Background(), "T202312010001")
+	assert.Nil(t, err)
+	assert.Equal(t, TransferFail, status.State)
+	assert.Equal(t, Amount(100), status.Amount)
+	assert.Equal(t, "00131040", status.SubCode)
+	assert.Equal(t, "收款账户户名不符", status.SubMsg)
+
+	_, err = cli.TransferQuery(context.Background(), "")
+	assert.EqualError(t, err, "order_id is required")
+}
@@ -3,35 +3,74 @@ package soopay
 import (
 	"crypto/tls"
 	"encoding/pem"
+	"errors"
 	"os"
 	"path/filepath"
+	"time"
 
 	"golang.org/x/crypto/pkcs12"
+	gopkcs12 "software.sslmate.com/src/go-pkcs12"
 )
 
 const OK = "0000"
 
-// LoadCertFromPfxFile 通过pfx(p12)证书文件生成TLS证书
-// 注意：证书需采用「TripleDES-SHA1」加密方式
-func LoadCertFromPfxFile(filename, password string) (tls.Certificate, error) {
-	fail := func(err error) (tls.Certificate, error) { return tls.Certificate{}, err }
+// 网关时间格式及时区（北京时间）
+const gatewayTimeLayout = "20060102150405"
 
-	certPath, err := filepath.Abs(filepath.Clean(filename))
+var gatewayLocation = time.FixedZone("CST", 8*3600)
 
-	if err != nil {
-		return fail(err)
+// FormatGatewayTime 按网关时间格式（yyyyMMddHHmmss，北京时间）格式化时间
+func FormatGatewayTime(t time.Time) string {
+	return t.In(gatewayLocation).Format(gatewayTimeLayout)
+}
+
+// ParseGatewayTime 解析网关返回的时间（yyyyMMddHHmmss，北京时间），空值返回零值
+func ParseGatewayTime(s string) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, nil
 	}
 
-	pfxdata, err := os.ReadFile(certPath)
+	return time.ParseInLocation(gatewayTimeLayout, s, gatewayLocation)
+}
+
+// ErrPfxPassword pfx(p12)证书密码错误
+var ErrPfxPassword = errors.New("pfx: incorrect password")
 
+// LoadCertFromPfx 通过pfx(p12)证书内容生成TLS证书
+// 支持「AES-256-CBC」(PBES2) 及「TripleDES-SHA1」等加密方式
+func LoadCertFromPfx(pfxData []byte, password string) (tls.Certificate, error) {
+	key, cert, caCerts, err := gopkcs12.DecodeChain(pfxData, password)
 	if err != nil {
-		return fail(err)
+		if errors.Is(err, gopkcs12.ErrIncorrectPassword) {
+			return tls.Certificate{}, ErrPfxPassword
+		}
+
+		// fallback to legacy decoding
+		return loadLegacyPfx(pfxData, password)
+	}
+
+	tlsCert := tls.Certificate{
+		Certificate: [][]byte{cert.Raw},
+		PrivateKey:  key,
+		Leaf:        cert,
+	}
+
+	for _, v := range caCerts {
+		tlsCert.Certificate = append(tlsCert.Certificate, v.Raw)
 	}
 
-	blocks, err := pkcs12.ToPEM(pfxdata, password)
+	return tlsCert, nil
+}
 
+// loadLegacyPfx 通过「TripleDES-SHA1」加密的pfx(p12)证书内容生成TLS证书
+func loadLegacyPfx(pfxData []byte, password string) (tls.Certificate, error) {
+	blocks, err := pkcs12.ToPEM(pfxData, password)
 	if err != nil {
-		return fail(err)
+		if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+			return tls.Certificate{}, ErrPfxPassword
+		}
+
+		return tls.Certificate{}, err
 	}
 
 	pemData := make([]byte, 0)
@@ -42,3 +81,18 @@ func LoadCertFromPfxFile(filename, password string) (tls.Certificate, error) {
 
 	return tls.X509KeyPair(pemData, pemData)
 }
+
+// LoadCertFromPfxFile 通过pfx(p12)证书文件生成TLS证书
+func LoadCertFromPfxFile(filename, password string) (tls.Certificate, error) {
+	certPath, err := filepath.Abs(filepath.Clean(filename))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	pfxdata, err := os.ReadFile(certPath)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return LoadCertFromPfx(pfxdata, password)
+}
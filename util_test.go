@@ -0,0 +1,48 @@
+package soopay
+
+import (
+	"crypto/rsa"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCertFromPfxFile(t *testing.T) {
+	prvKey, err := NewPrivateKeyFromPem(testPrivateKey)
+	assert.Nil(t, err)
+
+	for _, f := range []string{"testdata/cert_3des.pfx", "testdata/cert_aes.pfx"} {
+		cert, err := LoadCertFromPfxFile(f, "123456")
+		assert.Nil(t, err, f)
+		assert.True(t, prvKey.key.Equal(cert.PrivateKey.(*rsa.PrivateKey)), f)
+
+		_, err = LoadCertFromPfxFile(f, "654321")
+		assert.ErrorIs(t, err, ErrPfxPassword, f)
+	}
+
+	// legacy
+	pfxData, err := os.ReadFile("testdata/cert_3des.pfx")
+	assert.Nil(t, err)
+
+	cert, err := loadLegacyPfx(pfxData, "123456")
+	assert.Nil(t, err)
+	assert.True(t, prvKey.key.Equal(cert.PrivateKey.(*rsa.PrivateKey)))
+}
+
+func TestGatewayTime(t *testing.T) {
+	tm := time.Date(2023, 12, 1, 7, 30, 45, 0, time.UTC)
+	assert.Equal(t, "20231201153045", FormatGatewayTime(tm))
+
+	v, err := ParseGatewayTime("20231201153045")
+	assert.Nil(t, err)
+	assert.True(t, tm.Equal(v))
+
+	v, err = ParseGatewayTime("")
+	assert.Nil(t, err)
+	assert.True(t, v.IsZero())
+
+	_, err = ParseGatewayTime("2023-12-01 15:30:45")
+	assert.NotNil(t, err)
+}
@@ -1,8 +1,15 @@
 package soopay
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -19,6 +26,56 @@ func (v V) Get(key string) string {
 	return v[key]
 }
 
+// GetInt 获取值并转换为 int
+func (v V) GetInt(key string) (int, error) {
+	i, err := v.GetInt64(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(i), nil
+}
+
+// GetInt64 获取值并转换为 int64
+func (v V) GetInt64(key string) (int64, error) {
+	s, ok := v[key]
+	if !ok {
+		return 0, fmt.Errorf("key %q is not found", key)
+	}
+
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("key %q has malformed int value %q", key, s)
+	}
+
+	return i, nil
+}
+
+// MustGetInt64 获取值并转换为 int64；若发生错误，则Panic
+func (v V) MustGetInt64(key string) int64 {
+	i, err := v.GetInt64(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return i
+}
+
+// GetFloat 获取值并转换为 float64
+func (v V) GetFloat(key string) (float64, error) {
+	s, ok := v[key]
+	if !ok {
+		return 0, fmt.Errorf("key %q is not found", key)
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("key %q has malformed float value %q", key, s)
+	}
+
+	return f, nil
+}
+
 // Del 删除Key
 func (v V) Del(key string) {
 	delete(v, key)
@@ -31,9 +88,71 @@ func (v V) Has(key string) bool {
 	return ok
 }
 
+// Clone 返回 V 的浅拷贝（nil 返回空 V）
+func (v V) Clone() V {
+	ret := make(V, len(v))
+
+	for k, val := range v {
+		ret[k] = val
+	}
+
+	return ret
+}
+
+// Merge 合并 v 与 others，返回新的 V，不修改原数据；key相同时后者覆盖前者（others 按顺序覆盖 v）
+func (v V) Merge(others ...V) V {
+	ret := v.Clone()
+
+	for _, other := range others {
+		for k, val := range other {
+			ret[k] = val
+		}
+	}
+
+	return ret
+}
+
+// MarshalJSON 实现 json.Marshaler 接口
+func (v V) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string(v))
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口；数字、布尔值将转换为字符串，null 转换为空字符串
+func (v *V) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return err
+	}
+
+	ret := make(V, len(m))
+
+	for k, val := range m {
+		switch x := val.(type) {
+		case string:
+			ret[k] = x
+		case json.Number:
+			ret[k] = x.String()
+		case bool:
+			ret[k] = strconv.FormatBool(x)
+		case nil:
+			ret[k] = ""
+		default:
+			return fmt.Errorf("key %q has unsupported value type %T", k, val)
+		}
+	}
+
+	*v = ret
+
+	return nil
+}
+
 // Encode 通过自定义的符号和分隔符按照key的ASCII码升序格式化为字符串。
 // 例如：("=", "&") ---> bar=baz&foo=quux；
 // 例如：(":", "#") ---> bar:baz#foo:quux；
+// 注意：网关的签名串按key的ASCII码升序拼接，签名和验签时请勿通过 WithSortMode 或 WithSortFunc 修改排序方式
 func (v V) Encode(sym, sep string, options ...VEncOption) string {
 	if len(v) == 0 {
 		return ""
@@ -53,7 +172,14 @@ func (v V) Encode(sym, sep string, options ...VEncOption) string {
 			keys = append(keys, k)
 		}
 	}
-	sort.Strings(keys)
+	switch {
+	case opts.sortFunc != nil:
+		sort.Slice(keys, func(i, j int) bool { return opts.sortFunc(keys[i], keys[j]) })
+	case opts.sortMode == SortDesc:
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	default:
+		sort.Strings(keys)
+	}
 
 	var buf strings.Builder
 
@@ -95,6 +221,86 @@ func (v V) Encode(sym, sep string, options ...VEncOption) string {
 	return buf.String()
 }
 
+// ParseV 解析 `Encode("=", "&", WithKVEscape())` 格式化的字符串，K-V 均会 QueryUnescape；
+// 例如：bar=baz&foo=quux ---> V{"bar": "baz", "foo": "quux"}；
+// 注意：仅有key（如：bar=baz&foo）时值为空字符串，key重复时以最后一个为准
+func ParseV(encoded string) (V, error) {
+	v := make(V)
+
+	for len(encoded) != 0 {
+		var kv string
+
+		kv, encoded, _ = strings.Cut(encoded, "&")
+		if len(kv) == 0 {
+			continue
+		}
+
+		key, val, _ := strings.Cut(kv, "=")
+
+		k, err := url.QueryUnescape(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", key, err)
+		}
+
+		vv, err := url.QueryUnescape(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of key %q: %w", k, err)
+		}
+
+		v[k] = vv
+	}
+
+	return v, nil
+}
+
+// xmlToV 解析XML根元素下的子元素为 V，如：<xml><foo>bar</foo></xml> ---> V{"foo": "bar"}
+func xmlToV(b []byte) (V, error) {
+	v := make(V)
+
+	dec := xml.NewDecoder(bytes.NewReader(b))
+
+	var (
+		depth int
+		key   string
+		val   strings.Builder
+	)
+
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 {
+				key = t.Name.Local
+				val.Reset()
+			}
+		case xml.CharData:
+			if depth == 2 {
+				val.Write(t)
+			}
+		case xml.EndElement:
+			if depth == 2 {
+				v[key] = strings.TrimSpace(val.String())
+			}
+			depth--
+		}
+	}
+
+	if depth != 0 {
+		return nil, errors.New("unexpected EOF of XML")
+	}
+
+	return v, nil
+}
+
 // VEmptyMode 值为空时的Encode模式
 type VEmptyMode int
 
@@ -104,9 +310,20 @@ const (
 	EmptyOnlyKey                   // 仅保留Key：bar=baz&foo
 )
 
+// VSortMode Encode时key的排序方式
+// 注意：V 基于 map 实现，不保留key的写入顺序
+type VSortMode int
+
+const (
+	SortAsc  VSortMode = iota // 默认：按key的ASCII码升序（网关签名串规则）
+	SortDesc                  // 按key的ASCII码降序
+)
+
 type vEncOptions struct {
 	escape     bool
 	emptyMode  VEmptyMode
+	sortMode   VSortMode
+	sortFunc   func(a, b string) bool
 	ignoreKeys map[string]struct{}
 }
 
@@ -127,6 +344,20 @@ func WithKVEscape() VEncOption {
 	}
 }
 
+// WithSortMode 设置Encode时key的排序方式
+func WithSortMode(mode VSortMode) VEncOption {
+	return func(o *vEncOptions) {
+		o.sortMode = mode
+	}
+}
+
+// WithSortFunc 设置Encode时key的自定义排序（less 返回 a 是否应排在 b 之前），优先于 WithSortMode
+func WithSortFunc(less func(a, b string) bool) VEncOption {
+	return func(o *vEncOptions) {
+		o.sortFunc = less
+	}
+}
+
 // WithIgnoreKeys 设置Encode时忽略的key
 func WithIgnoreKeys(keys ...string) VEncOption {
 	return func(o *vEncOptions) {
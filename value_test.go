@@ -1,6 +1,7 @@
 package soopay
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +37,131 @@ func TestV(t *testing.T) {
 	assert.Equal(t, "bar=baz&foo=", v3.Encode("=", "&", WithIgnoreKeys("hello")))
 	assert.Equal(t, "bar=baz", v3.Encode("=", "&", WithIgnoreKeys("hello"), WithEmptyMode(EmptyIgnore)))
 }
+
+func TestVHasDel(t *testing.T) {
+	v := V{"foo": "bar", "empty": ""}
+
+	// 值为空时同样视为存在
+	assert.True(t, v.Has("foo"))
+	assert.True(t, v.Has("empty"))
+	assert.False(t, v.Has("none"))
+
+	v.Del("foo")
+	v.Del("none")
+	assert.False(t, v.Has("foo"))
+	assert.Equal(t, V{"empty": ""}, v)
+
+	assert.False(t, V(nil).Has("foo"))
+	V(nil).Del("foo")
+}
+
+func TestVGetNumber(t *testing.T) {
+	v := V{"amount": "100", "rate": "0.38", "name": "foo"}
+
+	i, err := v.GetInt64("amount")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), i)
+
+	n, err := v.GetInt("amount")
+	assert.Nil(t, err)
+	assert.Equal(t, 100, n)
+
+	f, err := v.GetFloat("rate")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.38, f)
+
+	_, err = v.GetInt64("name")
+	assert.EqualError(t, err, `key "name" has malformed int value "foo"`)
+
+	_, err = v.GetFloat("fee")
+	assert.EqualError(t, err, `key "fee" is not found`)
+
+	assert.Equal(t, int64(100), v.MustGetInt64("amount"))
+	assert.Panics(t, func() { v.MustGetInt64("name") })
+}
+
+func TestVClone(t *testing.T) {
+	v := V{"order_id": "202312010001", "amount": "100"}
+
+	v2 := v.Clone()
+	assert.Equal(t, v, v2)
+
+	v2.Set("sign", "xxx")
+	assert.False(t, v.Has("sign"))
+
+	var v3 V
+	assert.NotNil(t, v3.Clone())
+}
+
+func TestVMerge(t *testing.T) {
+	base := V{"channel": "web", "store_id": "S01"}
+	overlay := V{"store_id": "S02", "order_id": "202312010001"}
+
+	v := base.Merge(overlay, V{"order_id": "202312010002"})
+	assert.Equal(t, V{"channel": "web", "store_id": "S02", "order_id": "202312010002"}, v)
+
+	// 不修改原数据
+	assert.Equal(t, V{"channel": "web", "store_id": "S01"}, base)
+	assert.Equal(t, V{"store_id": "S02", "order_id": "202312010001"}, overlay)
+
+	assert.Equal(t, V{"foo": "bar"}, V(nil).Merge(V{"foo": "bar"}))
+}
+
+func TestVJSON(t *testing.T) {
+	v := V{"order_id": "202312010001", "amount": "100", "mer_priv": ""}
+
+	b, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"order_id":"202312010001","amount":"100","mer_priv":""}`, string(b))
+
+	var v2 V
+	assert.Nil(t, json.Unmarshal(b, &v2))
+	assert.Equal(t, v, v2)
+
+	var v3 V
+	assert.Nil(t, json.Unmarshal([]byte(`{"amount":100,"paid":true,"mer_priv":null}`), &v3))
+	assert.Equal(t, V{"amount": "100", "paid": "true", "mer_priv": ""}, v3)
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"extra":{"a":"b"}}`), &v3))
+}
+
+func TestVSort(t *testing.T) {
+	v := V{"foo": "quux", "bar": "baz", "hello": "world"}
+
+	assert.Equal(t, "bar=baz&foo=quux&hello=world", v.Encode("=", "&"))
+	assert.Equal(t, "bar=baz&foo=quux&hello=world", v.Encode("=", "&", WithSortMode(SortAsc)))
+	assert.Equal(t, "hello=world&foo=quux&bar=baz", v.Encode("=", "&", WithSortMode(SortDesc)))
+
+	order := map[string]int{"hello": 0, "bar": 1, "foo": 2}
+	assert.Equal(t, "hello=world&bar=baz&foo=quux", v.Encode("=", "&", WithSortFunc(func(a, b string) bool {
+		return order[a] < order[b]
+	})))
+}
+
+func TestParseV(t *testing.T) {
+	v := V{"order_id": "202312010001", "goods_inf": "测试 & 订单", "notify_url": "https://example.com/notify?a=1", "mer_priv": ""}
+
+	v2, err := ParseV(v.Encode("=", "&", WithKVEscape()))
+	assert.Nil(t, err)
+	assert.Equal(t, v, v2)
+
+	v3, err := ParseV("bar=baz&foo&&bar=quux")
+	assert.Nil(t, err)
+	assert.Equal(t, V{"bar": "quux", "foo": ""}, v3)
+
+	v4, err := ParseV("")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(v4))
+
+	_, err = ParseV("bar=%zz")
+	assert.NotNil(t, err)
+}
+
+func TestXMLToV(t *testing.T) {
+	v, err := xmlToV([]byte(`<xml><ret_code>0000</ret_code><ret_msg><![CDATA[成功 & ok]]></ret_msg><mer_priv/></xml>`))
+	assert.Nil(t, err)
+	assert.Equal(t, V{"ret_code": "0000", "ret_msg": "成功 & ok", "mer_priv": ""}, v)
+
+	_, err = xmlToV([]byte(`<xml><ret_code>0000</ret_code>`))
+	assert.NotNil(t, err)
+}